@@ -1,134 +1,79 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 
-	"container/list"
+	"github.com/kenthklui/adventofcode/util"
 )
 
-func readInput() []string {
-	lines := make([]string, 0)
-
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	if scanner.Err() != nil {
-		panic(scanner.Err())
-	}
-
-	return lines
-}
-
-func CupString(cups *list.List, startValue int) string {
-	var b strings.Builder
-
-	for c := cups.Front(); c != nil; c = c.Next() {
-		fmt.Fprintf(&b, "%d", c.Value.(int))
-	}
-
-	return b.String()
-}
-
 type Circle struct {
-	min, max int
-	cups     *list.List
-}
-
-func (c *Circle) String() string {
-	return CupString(c.cups, 0)
+	min, max, front int
+	cups            *util.Ring
 }
 
-func readCircle(input []string) *Circle {
-	cups := list.New()
+func readCircle(input []string, realMax int) *Circle {
+	order := make([]int, 0, realMax)
 	min, max := 9, 1
-
 	for i := range input[0] {
-		if num, err := strconv.Atoi(input[0][i : i+1]); err == nil {
-			if min > num {
-				min = num
-			}
-			if max < num {
-				max = num
-			}
-
-			cups.PushBack(num)
-		} else {
+		num, err := strconv.Atoi(input[0][i : i+1])
+		if err != nil {
 			panic(err)
 		}
+		if min > num {
+			min = num
+		}
+		if max < num {
+			max = num
+		}
+		order = append(order, num)
 	}
-
-	return &Circle{min, max, cups}
-}
-
-func (c *Circle) Move() {
-	current := c.cups.Front()
-
-	pickup := make([]int, 3)
-
-	for i := 0; i < 3; i++ {
-		next := current.Next()
-		pickup[i] = c.cups.Remove(next).(int)
+	for i := max + 1; i <= realMax; i++ {
+		order = append(order, i)
+	}
+	if realMax < max {
+		realMax = max
 	}
 
-	destValue := current.Value.(int)
-	for {
-		if destValue == c.min {
-			destValue = c.max
-		} else {
-			destValue--
-		}
-
-		found := false
-		for i := 0; i < 3; i++ {
-			found = found || (pickup[i] == destValue)
-		}
+	return &Circle{min, realMax, order[0], util.NewRing(realMax+1, order)}
+}
 
-		if !found {
-			break
-		}
+func (c *Circle) NextDest(dest int) int {
+	dest--
+	if dest < c.min {
+		dest = c.max
 	}
+	return dest
+}
 
-	dest := current
-	for dest.Value.(int) != destValue {
-		dest = dest.Next()
-	}
+func (c *Circle) Move() {
+	first := c.cups.Next(c.front)
+	second := c.cups.Next(first)
+	last := c.cups.Next(second)
 
-	for i := 0; i < 3; i++ {
-		dest = c.cups.InsertAfter(pickup[i], dest)
+	dest := c.NextDest(c.front)
+	for dest == first || dest == second || dest == last {
+		dest = c.NextDest(dest)
 	}
 
-	c.cups.PushBack(c.cups.Remove(c.cups.Front()))
+	c.cups.Splice(first, last, dest)
+	c.front = c.cups.Next(c.front)
 }
 
 func (c *Circle) Solution() string {
 	var b strings.Builder
-
-	l := list.New()
-	l.PushBackList(c.cups)
-	l.PushBackList(c.cups)
-
-	start := l.Front()
-	for start.Value.(int) != 1 {
-		start = start.Next()
-	}
-
-	for e := start.Next(); e.Value.(int) != 1; e = e.Next() {
-		fmt.Fprintf(&b, "%d", e.Value.(int))
+	for _, cup := range c.cups.Values(1)[1:] {
+		fmt.Fprintf(&b, "%d", cup)
 	}
-
 	return b.String()
 }
 
 func main() {
 	moves := 100
 
-	input := readInput()
-	c := readCircle(input)
+	input := util.StdinReadlines()
+	c := readCircle(input, 0)
 
 	for i := 0; i < moves; i++ {
 		c.Move()
@@ -1,158 +1,64 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-)
-
-func readInput() []string {
-	lines := make([]string, 0)
-
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	if scanner.Err() != nil {
-		panic(scanner.Err())
-	}
-
-	return lines
-}
-
-func parseDirection(dir string) (int, int) {
-	switch dir {
-	case "e":
-		return 0, 1
-	case "w":
-		return 0, -1
-	case "se":
-		return 1, 0
-	case "sw":
-		return 1, -1
-	case "ne":
-		return -1, 1
-	case "nw":
-		return -1, 0
-	}
 
-	return 0, 0
-}
+	"github.com/kenthklui/adventofcode/util"
+	"github.com/kenthklui/adventofcode/util/automaton"
+	"github.com/kenthklui/adventofcode/util/hex"
+)
 
-// false -> white, true -> black
-func setTiles(input []string) map[int]bool {
-	tiles := make(map[int]bool)
+const days = 100
 
-	var dir string
-	var x, y, dx, dy int
+// layTiles flips the tile at the end of each path; the set holds black tiles.
+func layTiles(input []string) map[hex.Hex]bool {
+	black := make(map[hex.Hex]bool)
 	for _, line := range input {
-		x, y = 0, 0
-		for _, b := range line {
-			dir += string(b)
-
-			switch b {
-			case 'e':
-				dx, dy = parseDirection(dir)
-				x += dx
-				y += dy
-				dir = ""
-			case 'w':
-				dx, dy = parseDirection(dir)
-				x += dx
-				y += dy
-				dir = ""
-			}
+		path, err := hex.ParsePath(line)
+		if err != nil {
+			panic(err)
 		}
 
-		key := x*32768 + y
-		if _, ok := tiles[key]; ok {
-			tiles[key] = !tiles[key]
+		tile := hex.Hex{}.Walk(path)
+		if black[tile] {
+			delete(black, tile)
 		} else {
-			tiles[key] = true
+			black[tile] = true
 		}
 	}
-
-	return tiles
+	return black
 }
 
-func flipTiles(tiles map[int]bool) map[int]bool {
-	newTiles := make(map[int]bool)
-
-	for key := range tiles {
-		neighborhood := []int{
-			key - 32768,
-			key - 32767,
-			key - 1,
-			key,
-			key + 1,
-			key + 32767,
-			key + 32768,
-		}
-
-		for _, newKey := range neighborhood {
-			if _, ok := newTiles[newKey]; ok {
-				continue
+// render draws the black tiles of the floor's bounding box.
+func render(floor *automaton.Automaton) string {
+	lo, hi := floor.Bounds()
+	cells := make(map[hex.Hex]byte)
+	for q := lo[0]; q <= hi[0]; q++ {
+		for r := lo[1]; r <= hi[1]; r++ {
+			if floor.Get(automaton.P(q, r)) == 1 {
+				cells[hex.Hex{Q: q, R: r}] = '#'
 			}
-
-			blackNeighbors := countBlackNeighbors(newKey, tiles)
-
-			black, ok := tiles[newKey]
-			if ok && black { // black
-				if blackNeighbors == 0 || blackNeighbors > 2 {
-					newTiles[newKey] = false
-				} else {
-					newTiles[newKey] = true
-				}
-			} else { // white
-				if blackNeighbors == 2 {
-					newTiles[newKey] = true
-				} else {
-					newTiles[newKey] = false
-				}
-			}
-		}
-	}
-
-	return newTiles
-}
-
-func countBlackNeighbors(key int, tiles map[int]bool) int {
-	blackNeighbors := 0
-	neighbors := []int{
-		key - 32768,
-		key - 32767,
-		key - 1,
-		key + 1,
-		key + 32767,
-		key + 32768,
-	}
-
-	for _, neighbor := range neighbors {
-		if tile, ok := tiles[neighbor]; ok && tile {
-			blackNeighbors++
 		}
 	}
-
-	return blackNeighbors
-}
-
-func countBlack(tiles map[int]bool) int {
-	black := 0
-	for _, t := range tiles {
-		if t {
-			black++
-		}
-	}
-
-	return black
+	return hex.Render(cells, '.')
 }
 
 func main() {
-	input := readInput()
-	tiles := setTiles(input)
+	input := util.StdinReadlines()
+	verbose := len(os.Args) >= 2 && os.Args[1] == "-v"
+
+	// Black tiles with 0 or more than 2 black neighbours turn white; white
+	// tiles with exactly 2 turn black. automaton.Hex shares hex's axes.
+	floor := automaton.NewDense(2, automaton.Hex(), automaton.Life([]int{2}, []int{1, 2}))
+	floor.Grow = true
+	for tile := range layTiles(input) {
+		floor.Set(automaton.P(tile.Q, tile.R), 1)
+	}
 
-	for i := 1; i <= 100; i++ {
-		tiles = flipTiles(tiles)
+	floor.Run(days)
+	if verbose {
+		fmt.Print(render(floor))
 	}
-	fmt.Printf("%d\n", countBlack(tiles))
+	fmt.Println(floor.Count(1))
 }
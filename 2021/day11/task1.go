@@ -1,141 +1,80 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+
+	"github.com/kenthklui/adventofcode/util"
+	"github.com/kenthklui/adventofcode/util/automaton"
 )
 
-type octopus struct {
-	energy    int
-	flashed   bool
-	neighbors []*octopus
-}
+// Energies 0 to 9 are their own states. An octopus that goes past 9 is
+// flashing for one round of the cascade, then spent until the step ends.
+const (
+	flashing = 10 + iota
+	spent
+	outside // beyond the grid, where nothing changes
+)
 
-func (o octopus) String() string {
-	f := 0
-	if o.flashed {
-		f = 1
+// charge raises every octopus's energy by one.
+func charge(state int, _ []int) int {
+	if state < flashing {
+		return state + 1
 	}
-	return fmt.Sprintf("{%d %d %d}", o.energy, f, len(o.neighbors))
+	return state
 }
 
-func (o *octopus) Flash() int {
-	o.flashed = true
-
-	flashes := 1
-	for _, n := range o.neighbors {
-		flashes += n.Increment()
+// cascade raises an octopus's energy by one for each neighbour flashing
+// now. Each octopus flashes at most once a step.
+func cascade(state int, neighbours []int) int {
+	switch {
+	case state == flashing || state == spent:
+		return spent
+	case state == outside:
+		return outside
 	}
-
-	return flashes
-}
-
-func (o *octopus) Increment() int {
-	if o.flashed {
-		return 0
+	if state += automaton.Tally(neighbours, flashing); state > 9 {
+		return flashing
 	}
-
-	o.energy++
-	if o.energy > 9 {
-		return o.Flash()
-	}
-
-	return 0
-}
-
-func (o *octopus) Reset() {
-	if o.flashed {
-		o.energy = 0
-		o.flashed = false
-	}
-}
-
-func (o *octopus) AddNeighbor(neighbor *octopus) {
-	o.neighbors = append(o.neighbors, neighbor)
-}
-
-type swarm struct {
-	octopi   []*octopus
-	row, col int
+	return state
 }
 
-func (s *swarm) Print() {
-	fmt.Println(s.octopi[:s.row])
-}
-
-func (s *swarm) Step() int {
-	flashCount := 0
-	for _, o := range s.octopi {
-		flashCount += o.Increment()
-	}
-
-	for _, o := range s.octopi {
-		o.Reset()
+// rest drains the energy of every octopus that flashed.
+func rest(state int, _ []int) int {
+	if state == spent {
+		return 0
 	}
-
-	return flashCount
+	return state
 }
 
-func NewSwarm(input []string) *swarm {
-	row := len(input)
-	col := len(input[0])
-
-	octopi := make([]*octopus, 0)
-	for i, line := range input {
-		for j, val := range line {
-			energy := int(val - '0')
-			o := &octopus{energy: energy}
-
-			neighbors := make([]*octopus, 0)
-			if i != 0 {
-				neighbors = append(neighbors, octopi[(i-1)*col+j])
-
-				if j != 0 {
-					neighbors = append(neighbors, octopi[(i-1)*col+j-1])
-				}
-
-				if j != col-1 {
-					neighbors = append(neighbors, octopi[(i-1)*col+j+1])
-				}
-			}
-			if j != 0 {
-				neighbors = append(neighbors, octopi[i*col+j-1])
-			}
-
-			for _, n := range neighbors {
-				n.AddNeighbor(o)
-				o.AddNeighbor(n)
-			}
-
-			octopi = append(octopi, o)
-		}
-	}
-
-	return &swarm{octopi, row, col}
+// step charges the octopuses, lets flashes spread until none are left, and
+// returns how many flashed.
+func step(cavern *automaton.Automaton) int {
+	cavern.Rule = charge
+	cavern.Step()
+	cavern.Rule = cascade
+	cavern.RunUntilStable(0)
+	flashes := cavern.Count(spent)
+	cavern.Rule = rest
+	cavern.Step()
+	return flashes
 }
 
-func readInput() []string {
-	lines := make([]string, 0)
-
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+func newCavern(input []string) *automaton.Automaton {
+	cavern := automaton.NewDense(2, automaton.Moore(2), charge)
+	cavern.Background = outside
+	if err := cavern.Load(input, "0123456789"); err != nil {
+		panic(err)
 	}
-	if scanner.Err() != nil {
-		panic(scanner.Err())
-	}
-
-	return lines
+	return cavern
 }
 
 func main() {
-	input := readInput()
-	s := NewSwarm(input)
+	input := util.StdinReadlines()
+	cavern := newCavern(input)
 
 	flashCount := 0
 	for i := 0; i < 100; i++ {
-		flashCount += s.Step()
+		flashCount += step(cavern)
 	}
 
 	fmt.Println(flashCount)
@@ -1,112 +1,31 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"strings"
-)
-
-func readInput() []string {
-	lines := make([]string, 0)
-
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	if scanner.Err() != nil {
-		panic(scanner.Err())
-	}
-
-	return lines
-}
-
-type instruction struct {
-	count, from, to int
-}
-
-type crates [][]byte
-
-func (c *crates) execute(ins instruction) {
-	for i := ins.count; i > 0; i-- {
-		c.move(ins.from, ins.to)
-	}
-}
-
-func (cc *crates) move(from, to int) {
-	c := *cc
-	n := len(c[from]) - 1
-	pop := c[from][n]
-	c[to] = append(c[to], pop)
-	c[from] = c[from][:n]
-}
-
-func (cc *crates) printTop() {
-	c := *cc
-	var b strings.Builder
-	for _, stack := range c {
-		if len(stack) == 0 {
-			continue
-		}
-		n := len(stack) - 1
-		b.WriteByte(stack[n])
-	}
 
-	fmt.Println(b.String())
-}
-
-func parseInput(input []string) (*crates, []instruction) {
-	linebreak := 0
-	for i, line := range input {
-		if len(line) == 0 {
-			linebreak = i
-			break
-		}
-	}
-
-	maxStackCount := 9
-	stacks := make(crates, maxStackCount)
-	for i := range stacks {
-		stacks[i] = make([]byte, 0)
-	}
-
-	for i := linebreak - 2; i >= 0; i-- {
-		line := input[i]
-		for j := 0; j < maxStackCount; j++ {
-			index := j*4 + 1
-			if index >= len(line) {
-				break
-			}
-
-			if line[index] != ' ' {
-				stacks[j] = append(stacks[j], line[index])
-			}
-		}
-	}
+	"github.com/kenthklui/adventofcode/util"
+	"github.com/kenthklui/adventofcode/util/crane"
+)
 
-	instructions := make([]instruction, 0)
-	var count, from, to int
-	for _, line := range input[linebreak+1:] {
-		n, err := fmt.Sscanf(line, "move %d from %d to %d", &count, &from, &to)
-		if err != nil {
-			panic(err)
-		} else if n != 3 {
-			panic("Failed to parse instruction")
+func main() {
+	input := util.StdinReadlines()
+	stacks, moves, err := crane.Parse(input)
+	if err != nil {
+		panic(err)
+	}
+
+	var watch func(int, crane.Move)
+	c := crane.New(stacks, crane.CrateMover9000)
+	if len(os.Args) >= 2 && os.Args[1] == "-v" {
+		fmt.Println(c.Stacks)
+		watch = func(_ int, m crane.Move) {
+			fmt.Printf("%v\n%v\n", m, c.Stacks)
 		}
-
-		instructions = append(instructions, instruction{count, from - 1, to - 1})
 	}
 
-	return &stacks, instructions
-}
-
-func main() {
-	input := readInput()
-	stacks, instructions := parseInput(input)
-
-	for _, ins := range instructions {
-		stacks.execute(ins)
+	if err := c.Run(moves, watch); err != nil {
+		panic(err)
 	}
-
-	stacks.printTop()
+	fmt.Println(c.Stacks.Top())
 }
@@ -1,140 +1,77 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"sort"
+	"path/filepath"
 	"strconv"
-	"strings"
+
+	"github.com/kenthklui/adventofcode/util"
+	"github.com/kenthklui/adventofcode/util/sand"
 )
 
-func readInput() []string {
-	lines := make([]string, 0)
+// Flags: -v prints the cave once the sand stops; -frames N prints it every N
+// grains as well; -pgm DIR writes those pictures to DIR as PGM images instead.
+func main() {
+	var verbose bool
+	var every int
+	var dir string
+	for i := 1; i < len(os.Args); i++ {
+		switch os.Args[i] {
+		case "-v":
+			verbose = true
+		case "-frames", "-pgm":
+			if i+1 == len(os.Args) {
+				panic(fmt.Sprintf("%s needs a value", os.Args[i]))
+			}
+			if i++; os.Args[i-1] == "-pgm" {
+				dir = os.Args[i]
+			} else if n, err := strconv.Atoi(os.Args[i]); err != nil || n < 0 {
+				panic(fmt.Sprintf("bad frame interval %q", os.Args[i]))
+			} else {
+				every = n
+			}
+		default:
+			panic(fmt.Sprintf("unknown argument %q", os.Args[i]))
+		}
+	}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+	input := util.StdinReadlines()
+	paths, err := sand.ParsePaths(input)
+	if err != nil {
+		panic(err)
 	}
-	if scanner.Err() != nil {
-		panic(scanner.Err())
+	c, err := sand.New(paths, []sand.Point{{X: 500, Y: 0}}, sand.Abyss)
+	if err != nil {
+		panic(err)
 	}
 
-	return lines
-}
-
-type rockMap map[int][]int
-
-func (rm rockMap) addRock(x, y int) {
-	if yList, ok := rm[x]; ok {
-		i := sort.SearchInts(yList, y)
-		if i == len(yList) {
-			rm[x] = append(yList, y)
-		} else if yList[i] == y {
+	show := func(name string) {
+		if dir == "" {
+			fmt.Printf("%s\n%v\n", name, c)
 			return
-		} else {
-			yList = append(yList[:i+1], yList[i:]...)
-			yList[i] = y
-			rm[x] = yList
-		}
-	} else {
-		rm[x] = []int{y}
-	}
-}
-
-func (rm rockMap) addSand() int {
-	sandX := 500
-	sandY := 0
-
-	sand := 0
-	for {
-		yList, ok := rm[sandX]
-		if !ok { // to the abyss you go
-			break
 		}
-		yIndex := sort.SearchInts(yList, sandY)
-		if yIndex >= len(yList) { // to the abyss you go
-			break
+		f, err := os.Create(filepath.Join(dir, name+".pgm"))
+		if err != nil {
+			panic(err)
 		}
-		landOnY := yList[yIndex]
-
-		leftList, ok := rm[sandX-1]
-		if !ok { // to the abyss you go
-			break
+		defer f.Close()
+		if err := c.WritePGM(f, 4); err != nil {
+			panic(err)
 		}
-		leftIndex := sort.SearchInts(leftList, landOnY)
-		if leftIndex >= len(leftList) { // to the abyss you go
-			break
-		} else if leftList[leftIndex] != landOnY { // left not occupied
-			sandX--
-			sandY = landOnY
-			continue
-		}
-
-		rightList, ok := rm[sandX+1]
-		if !ok { // to the abyss you go
-			break
-		}
-		rightIndex := sort.SearchInts(rightList, landOnY)
-		if rightIndex >= len(rightList) { // to the abyss you go
-			break
-		} else if rightList[rightIndex] != landOnY { // right not occupied
-			sandX++
-			sandY = landOnY
-			continue
-		}
-
-		// both left and right occupied
-		rm.addRock(sandX, landOnY-1)
-		sand++
-		sandX, sandY = 500, 0
 	}
 
-	return sand
-}
-
-func parseInput(input []string) rockMap {
-	rm := make(rockMap)
-	for _, line := range input {
-		rocks := strings.Split(line, " -> ")
-		point := strings.Split(rocks[0], ",")
-		x1, _ := strconv.Atoi(point[0])
-		y1, _ := strconv.Atoi(point[1])
-
-		for _, rock := range rocks[1:] {
-			point = strings.Split(rock, ",")
-			x2, _ := strconv.Atoi(point[0])
-			y2, _ := strconv.Atoi(point[1])
-
-			if x1 < x2 {
-				for x := x1; x <= x2; x++ {
-					rm.addRock(x, y1)
-				}
-			} else if x1 > x2 {
-				for x := x2; x <= x1; x++ {
-					rm.addRock(x, y1)
-				}
-			} else if y1 < y2 {
-				for y := y1; y <= y2; y++ {
-					rm.addRock(x1, y)
-				}
-			} else if y1 > y2 {
-				for y := y2; y <= y1; y++ {
-					rm.addRock(x1, y)
-				}
-			} else {
-				panic("Two rocks are the same point")
+	var watch func(int)
+	if every > 0 {
+		watch = func(grains int) {
+			if grains%every == 0 {
+				show(fmt.Sprintf("frame%06d", grains))
 			}
-			x1 = x2
-			y1 = y2
 		}
 	}
-	return rm
-}
-
-func main() {
-	input := readInput()
-	rm := parseInput(input)
-
-	fmt.Println(rm.addSand())
+	c.Pour(watch)
+	if verbose || dir != "" {
+		show("final")
+	}
+	fmt.Println(c.Grains)
 }
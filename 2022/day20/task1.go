@@ -1,77 +1,30 @@
 package main
 
 import (
-	"bufio"
-	"container/list"
 	"fmt"
-	"os"
 	"strconv"
+
+	"github.com/kenthklui/adventofcode/util"
 )
 
+// mix moves each number by its own value, in original order. Labels in the
+// ring are indices into nums.
 func mix(nums []int) []int {
-	l := list.New()
-	elements := make([]*list.Element, 0, len(nums))
-
-	for _, n := range nums {
-		e := l.PushBack(n)
-		elements = append(elements, e)
-	}
-
-	for _, e := range elements {
-		val := e.Value.(int) % (len(nums) - 1)
-		curr := e
-
-		if val > 0 {
-			var next *list.Element
-			for i := val; i > 0; i-- {
-				if curr.Next() != nil {
-					next = curr.Next()
-				} else {
-					next = l.Front()
-				}
-				curr = next
-			}
-			if curr == l.Back() {
-				l.MoveBefore(e, l.Front())
-			} else {
-				l.MoveAfter(e, curr)
-			}
-		} else if val < 0 {
-			var prev *list.Element
-			for i := val; i < 0; i++ {
-				if curr.Prev() != nil {
-					prev = curr.Prev()
-				} else {
-					prev = l.Back()
-				}
-				curr = prev
-			}
-			if curr == l.Front() {
-				l.MoveAfter(e, l.Back())
-			} else {
-				l.MoveBefore(e, curr)
-			}
-		}
+	order := make([]int, len(nums))
+	for i := range order {
+		order[i] = i
 	}
+	ring := util.NewOrderRing(order)
 
-	newNums := make([]int, 0, len(nums))
-	for e := l.Front(); e != nil; e = e.Next() {
-		newNums = append(newNums, e.Value.(int))
+	for i, n := range nums {
+		ring.Move(i, n)
 	}
 
-	return newNums
-}
-
-func readInput() []string {
-	lines := make([]string, 0)
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	if scanner.Err() != nil {
-		panic(scanner.Err())
+	mixed := make([]int, 0, len(nums))
+	for _, i := range ring.Values(0) {
+		mixed = append(mixed, nums[i])
 	}
-	return lines
+	return mixed
 }
 
 func parseInput(input []string) []int {
@@ -101,7 +54,7 @@ func zeroIndex(nums []int) int {
 }
 
 func main() {
-	input := readInput()
+	input := util.StdinReadlines()
 	nums := parseInput(input)
 	mixed := mix(nums)
 
@@ -1,80 +1,34 @@
 package main
 
 import (
-	"bufio"
-	"container/list"
 	"fmt"
-	"os"
 	"strconv"
+
+	"github.com/kenthklui/adventofcode/util"
 )
 
+// mix moves each number by its own value, in original order, cycles times.
+// Labels in the ring are indices into nums.
 func mix(nums []int, key, cycles int) []int {
-	l := list.New()
-	elements := make([]*list.Element, 0, len(nums))
-
-	for _, n := range nums {
-		newVal := n * key
-		e := l.PushBack(newVal)
-		elements = append(elements, e)
+	keyed := make([]int, len(nums))
+	order := make([]int, len(nums))
+	for i, n := range nums {
+		keyed[i] = n * key
+		order[i] = i
 	}
+	ring := util.NewOrderRing(order)
 
 	for cycle := 0; cycle < cycles; cycle++ {
-		for _, e := range elements {
-			val := e.Value.(int) % (len(nums) - 1)
-			curr := e
-
-			if val > 0 {
-				var next *list.Element
-				for i := val; i > 0; i-- {
-					if curr.Next() != nil {
-						next = curr.Next()
-					} else {
-						next = l.Front()
-					}
-					curr = next
-				}
-				if curr == l.Back() {
-					l.MoveBefore(e, l.Front())
-				} else {
-					l.MoveAfter(e, curr)
-				}
-			} else if val < 0 {
-				var prev *list.Element
-				for i := val; i < 0; i++ {
-					if curr.Prev() != nil {
-						prev = curr.Prev()
-					} else {
-						prev = l.Back()
-					}
-					curr = prev
-				}
-				if curr == l.Front() {
-					l.MoveAfter(e, l.Back())
-				} else {
-					l.MoveBefore(e, curr)
-				}
-			}
+		for i, n := range keyed {
+			ring.Move(i, n)
 		}
 	}
 
-	newNums := make([]int, 0, len(nums))
-	for e := l.Front(); e != nil; e = e.Next() {
-		newNums = append(newNums, e.Value.(int))
-	}
-
-	return newNums
-}
-
-func readInput() []string {
-	lines := make([]string, 0)
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	if scanner.Err() != nil {
-		panic(scanner.Err())
+	mixed := make([]int, 0, len(nums))
+	for _, i := range ring.Values(0) {
+		mixed = append(mixed, keyed[i])
 	}
-	return lines
+	return mixed
 }
 
 func parseInput(input []string) []int {
@@ -107,7 +61,7 @@ const decryptionKey = 811589153
 const mixCycles = 10
 
 func main() {
-	input := readInput()
+	input := util.StdinReadlines()
 	nums := parseInput(input)
 	mixed := mix(nums, decryptionKey, mixCycles)
 
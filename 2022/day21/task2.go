@@ -1,172 +1,51 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"strconv"
-	"strings"
-)
-
-type monkey interface {
-	name() string
-	value() int
-	setParent(monkey)
-	setChildren(monkeys map[string]monkey)
-	parentMonkey() monkey
-	// Given a goal value, and indicating the mutable child, what value should the child have?
-	requirement(int, monkey) int
-}
 
-type opMonkey struct {
-	nameStr               string
-	leftStr, rightStr, op string
-	parent, left, right   monkey
-}
+	"github.com/kenthklui/adventofcode/util"
+	"github.com/kenthklui/adventofcode/util/symbolic"
+)
 
-func (om *opMonkey) name() string { return om.nameStr }
-func (om *opMonkey) value() int {
-	switch om.op {
-	case "+":
-		return om.left.value() + om.right.value()
-	case "-":
-		return om.left.value() - om.right.value()
-	case "*":
-		return om.left.value() * om.right.value()
-	case "/":
-		return om.left.value() / om.right.value()
-	default:
-		panic("Invalid op")
-	}
-}
-func (om *opMonkey) setParent(m monkey) {
-	if om.parent != nil {
-		panic("Multiple parents")
-	}
-	om.parent = m
-}
-func (om *opMonkey) setChildren(monkeys map[string]monkey) {
-	om.left = monkeys[om.leftStr]
-	om.left.setParent(om)
-	om.right = monkeys[om.rightStr]
-	om.right.setParent(om)
-}
-func (om *opMonkey) parentMonkey() monkey { return om.parent }
+const unknown = "humn"
 
-func (om *opMonkey) requirement(goal int, child monkey) int {
-	if child.name() == om.left.name() {
-		switch om.op {
-		case "+":
-			return goal - om.right.value()
-		case "-":
-			return goal + om.right.value()
-		case "*":
-			return goal / om.right.value()
-		case "/":
-			return goal * om.right.value()
-		case "=":
-			return om.right.value()
-		default:
-			panic("Invalid op")
-		}
-	} else if child.name() == om.right.name() {
-		switch om.op {
-		case "+":
-			return goal - om.left.value()
-		case "-":
-			return om.left.value() - goal
-		case "*":
-			return goal / om.left.value()
-		case "/":
-			return om.left.value() / goal
-		case "=":
-			return om.left.value()
-		default:
-			panic("Invalid op")
-		}
+func main() {
+	input := util.StdinReadlines()
+	system, err := symbolic.ParseSystem(input)
+	if err != nil {
+		panic(err)
 	}
-	fmt.Println(child.name(), om.left.name(), om.right.name())
-	panic("Invalid monkey child")
-}
 
-type valMonkey struct {
-	nameStr string
-	val     int
-	parent  monkey
-}
-
-func (vm *valMonkey) name() string { return vm.nameStr }
-func (vm *valMonkey) value() int   { return vm.val }
-func (vm *valMonkey) setParent(m monkey) {
-	if vm.parent != nil {
-		panic("Multiple parents")
+	// root compares its two operands; humn is what we need to shout
+	system.Free(unknown)
+	eq, err := system.Equation("root")
+	if err != nil {
+		panic(err)
 	}
-	vm.parent = m
-}
-func (vm *valMonkey) setChildren(monkeys map[string]monkey) {}
-func (vm *valMonkey) parentMonkey() monkey                  { return vm.parent }
 
-func readInput() []string {
-	lines := make([]string, 0)
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	if scanner.Err() != nil {
-		panic(scanner.Err())
-	}
-	return lines
-}
-func (vm *valMonkey) requirement(goal int, child monkey) int {
-	return goal
-}
+	// Pass -v to show the equation being solved
+	if len(os.Args) >= 2 && os.Args[1] == "-v" {
+		simplified, err := eq.Simplify()
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(simplified)
 
-func parseInput(input []string) map[string]monkey {
-	monkeys := make(map[string]monkey)
-	for _, line := range input {
-		splits := strings.Split(line, " ")
-		name := strings.TrimRight(splits[0], ":")
-		if len(splits) == 2 {
-			value, _ := strconv.Atoi(splits[1])
-			monkeys[name] = &valMonkey{nameStr: name, val: value}
-		} else {
-			m := opMonkey{
-				nameStr:  name,
-				leftStr:  splits[1],
-				rightStr: splits[3],
-				op:       splits[2],
-			}
-			if name == "root" {
-				m.op = "="
-			}
-			monkeys[name] = &m
+		if l, err := eq.Linear(); err == nil {
+			fmt.Println(l, "= 0")
 		}
+		occurrences := simplified.Left.Occurrences()[unknown] + simplified.Right.Occurrences()[unknown]
+		fmt.Printf("%s occurs %d times\n", unknown, occurrences)
 	}
-	for _, monkey := range monkeys {
-		monkey.setChildren(monkeys)
-	}
-
-	return monkeys
-}
 
-func main() {
-	input := readInput()
-	monkeys := parseInput(input)
-
-	// Travel up the chain to find the path to root
-	human := monkeys["humn"]
-	path := make([]monkey, 0)
-	for curr := human; curr.parentMonkey() != nil; curr = curr.parentMonkey() {
-		path = append(path, curr)
+	solution, err := eq.Solve(unknown)
+	if err != nil {
+		panic(err)
 	}
-
-	// Travel down the chain to reverse engineer the required value
-	curr := monkeys["root"]
-	goal := 0
-	for i := len(path) - 1; i >= 0; i-- {
-		goal = curr.requirement(goal, path[i])
-		curr = path[i]
+	if !solution.IsInt() {
+		panic(fmt.Sprintf("%s must be an integer, got %s", unknown, solution.RatString()))
 	}
 
-	fmt.Println(goal)
+	fmt.Println(solution.RatString())
 }
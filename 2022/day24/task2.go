@@ -1,140 +1,30 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-)
-
-type mountain struct {
-	blizzards     [][]byte
-	height, width int
-}
-
-func NewMountain(height, width int) *mountain {
-	blizzards := make([][]byte, height)
-	for i := range blizzards {
-		blizzards[i] = make([]byte, width)
-	}
-
-	return &mountain{
-		blizzards: blizzards, height: height,
-		width: width,
-	}
-}
-
-func (m *mountain) clear(x, y, minute int) bool {
-	rowOffset, colOffset := minute%m.height, minute%m.width
-	if up := (y + rowOffset) % m.height; m.blizzards[up][x] == '^' {
-		return false
-	}
-	if down := (y - rowOffset + m.height) % m.height; m.blizzards[down][x] == 'v' {
-		return false
-	}
-	if left := (x + colOffset) % m.width; m.blizzards[y][left] == '<' {
-		return false
-	}
-	if right := (x - colOffset + m.width) % m.width; m.blizzards[y][right] == '>' {
-		return false
-	}
-	return true
-}
-
-func (m *mountain) next(x, y int) [][2]int {
-	neighbors := make([][2]int, 0, 5)
-	neighbors = append(neighbors, [2]int{x, y})
-	if x > 0 {
-		neighbors = append(neighbors, [2]int{x - 1, y})
-	}
-	if x < m.width-1 {
-		neighbors = append(neighbors, [2]int{x + 1, y})
-	}
-	if y > 0 {
-		neighbors = append(neighbors, [2]int{x, y - 1})
-	}
-	if y < m.height-1 {
-		neighbors = append(neighbors, [2]int{x, y + 1})
-	}
-	return neighbors
-}
-
-func (m *mountain) traverse(startMin int, forward bool) int {
-	var startX, startY, endX, endY int = 0, 0, m.width - 1, m.height - 1
-	if !forward {
-		startX, startY, endX, endY = endX, endY, startX, startY
-	}
 
-	prevReached, reached := make([][2]int, 0, m.height*m.width), make([][2]int, 0, m.height*m.width)
-	checked := make([][]bool, m.height)
-	for i := range checked {
-		checked[i] = make([]bool, m.width)
-	}
-
-	for min := startMin + 1; true; min++ {
-		checked[startY][startX] = true
-		if m.clear(startX, startY, min) {
-			reached = append(reached, [2]int{startX, startY})
-		}
-
-		for _, r := range prevReached {
-			if r[0] == endX && r[1] == endY {
-				return min
-			}
-
-			for _, n := range m.next(r[0], r[1]) {
-				if !checked[n[1]][n[0]] {
-					checked[n[1]][n[0]] = true
-					if m.clear(n[0], n[1], min) {
-						reached = append(reached, n)
-					}
-				}
-
-			}
-		}
+	"github.com/kenthklui/adventofcode/util"
+	"github.com/kenthklui/adventofcode/util/valley"
+)
 
-		prevReached, reached = reached, prevReached[:0]
-		for y, row := range checked {
-			for x := range row {
-				checked[y][x] = false
-			}
-		}
+func main() {
+	input := util.StdinReadlines()
+	v, err := valley.Parse(input)
+	if err != nil {
+		panic(err)
 	}
 
-	return 0
-}
-
-func readInput() []string {
-	lines := make([]string, 0)
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	if scanner.Err() != nil {
-		panic(scanner.Err())
+	// Back to the start for the forgotten snacks, then out again
+	route, err := v.Trip(0, v.Start, v.End, v.Start, v.End)
+	if err != nil {
+		panic(err)
 	}
-	return lines
-}
-
-func parseInput(input []string) *mountain {
-	height := len(input) - 2
-	width := len(input[0]) - 2
-	m := NewMountain(height, width)
-	for y, line := range input[1 : len(input)-1] {
-		for x, r := range line[1 : len(line)-1] {
-			m.blizzards[y][x] = byte(r)
+	if len(os.Args) >= 2 && os.Args[1] == "-v" {
+		for i, p := range route.Path {
+			fmt.Printf("Minute %d\n%s\n", route.Depart+i, v.Frame(route.Depart+i, p))
 		}
+		fmt.Println("Legs end at minutes", route.Arrivals)
 	}
-
-	return m
-}
-
-func main() {
-	input := readInput()
-	mount := parseInput(input)
-
-	done := mount.traverse(0, true)
-	done = mount.traverse(done, false)
-	done = mount.traverse(done, true)
-
-	fmt.Println(done)
+	fmt.Println(route.Arrive())
 }
@@ -0,0 +1,490 @@
+package automaton
+
+import (
+	"fmt"
+	"strings"
+)
+
+const MaxDims = 8
+
+// Point is a cell's coordinates. Axes past an automaton's Dims stay zero.
+type Point [MaxDims]int
+
+func P(coords ...int) Point {
+	var p Point
+	copy(p[:], coords)
+	return p
+}
+
+func (p Point) Add(o Point) Point {
+	for i := range p {
+		p[i] += o[i]
+	}
+	return p
+}
+
+func (p Point) manhattan() int {
+	sum := 0
+	for _, c := range p {
+		if c < 0 {
+			c = -c
+		}
+		sum += c
+	}
+	return sum
+}
+
+// Rule gives a cell's next state from its current one and its neighbours'
+// states, listed in the neighbourhood's order.
+type Rule func(state int, neighbours []int) int
+
+// Tally counts the neighbours in state.
+func Tally(neighbours []int, state int) int {
+	count := 0
+	for _, s := range neighbours {
+		if s == state {
+			count++
+		}
+	}
+	return count
+}
+
+// Life is a two-state totalistic rule: a dead cell (0) comes alive with a
+// live neighbour count in born, a live cell (1) survives with one in survive.
+func Life(born, survive []int) Rule {
+	return func(state int, neighbours []int) int {
+		counts := born
+		if state == 1 {
+			counts = survive
+		}
+
+		alive := Tally(neighbours, 1)
+		for _, c := range counts {
+			if alive == c {
+				return 1
+			}
+		}
+		return 0
+	}
+}
+
+// Automaton steps every cell of a grid by Rule at once. Sparse grids are
+// unbounded and store only cells that differ from Background; dense grids
+// store a box of cells and treat everything outside it as Background.
+type Automaton struct {
+	Dims          int
+	Neighbourhood Neighbourhood
+	Rule          Rule
+
+	// Background is the state of every cell not stored. Each step applies
+	// the rule to it too, so an infinite background may flip.
+	Background int
+	Generation int
+
+	// Dense grids only: Grow widens the box by the neighbourhood's reach
+	// every step, and Wrap joins opposite edges of the box into a torus
+	// instead.
+	Grow, Wrap bool
+
+	dense  bool
+	cells  map[Point]int // sparse
+	grid   []int         // dense, row-major over lo..hi
+	lo, hi Point         // inclusive bounds; for sparse grids of stored cells
+}
+
+func New(dims int, n Neighbourhood, rule Rule) *Automaton {
+	a := &Automaton{Dims: dims, Neighbourhood: n, Rule: rule, cells: make(map[Point]int)}
+	a.lo[0], a.hi[0] = 0, -1
+	return a
+}
+
+// NewDense starts with an empty box, which grows to fit cells as they are
+// set or loaded.
+func NewDense(dims int, n Neighbourhood, rule Rule) *Automaton {
+	a := &Automaton{Dims: dims, Neighbourhood: n, Rule: rule, dense: true}
+	a.lo[0], a.hi[0] = 0, -1
+	return a
+}
+
+// Load sets cells (row, col), at axes 0 and 1, from lines of characters; each
+// character's state is its index in states.
+func (a *Automaton) Load(lines []string, states string) error {
+	width := 0
+	for _, line := range lines {
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+	if a.dense && len(lines) > 0 && width > 0 {
+		a.include(P(0, 0), P(len(lines)-1, width-1))
+	}
+
+	for row, line := range lines {
+		for col := 0; col < len(line); col++ {
+			state := strings.IndexByte(states, line[col])
+			if state == -1 {
+				return fmt.Errorf("automaton: %q at row %d, column %d is not one of %q", line[col], row, col, states)
+			}
+			a.Set(P(row, col), state)
+		}
+	}
+	return nil
+}
+
+func (a *Automaton) empty() bool {
+	return a.lo[0] > a.hi[0]
+}
+
+// Bounds is the box of stored cells, inclusive.
+func (a *Automaton) Bounds() (lo, hi Point) {
+	return a.lo, a.hi
+}
+
+func (a *Automaton) wrap(p Point) Point {
+	if a.Wrap && !a.empty() {
+		for d := 0; d < a.Dims; d++ {
+			size := a.hi[d] - a.lo[d] + 1
+			p[d] = a.lo[d] + ((p[d]-a.lo[d])%size+size)%size
+		}
+	}
+	return p
+}
+
+// index finds p in a dense grid, which must already be wrapped.
+func (a *Automaton) index(p Point) (int, bool) {
+	i := 0
+	for d := 0; d < a.Dims; d++ {
+		if p[d] < a.lo[d] || p[d] > a.hi[d] {
+			return 0, false
+		}
+		i = i*(a.hi[d]-a.lo[d]+1) + p[d] - a.lo[d]
+	}
+	return i, true
+}
+
+func (a *Automaton) Get(p Point) int {
+	p = a.wrap(p)
+	if !a.dense {
+		if s, ok := a.cells[p]; ok {
+			return s
+		}
+		return a.Background
+	}
+	if i, ok := a.index(p); ok {
+		return a.grid[i]
+	}
+	return a.Background
+}
+
+// Set stores a cell's state, growing a dense grid's box to fit it.
+func (a *Automaton) Set(p Point, state int) {
+	p = a.wrap(p)
+	if !a.dense {
+		if state == a.Background {
+			if _, ok := a.cells[p]; ok {
+				delete(a.cells, p)
+				if a.onEdge(p) {
+					a.rebound()
+				}
+			}
+			return
+		}
+		a.cells[p] = state
+		a.extend(p)
+		return
+	}
+
+	if _, ok := a.index(p); !ok {
+		a.include(p, p)
+	}
+	i, _ := a.index(p)
+	a.grid[i] = state
+}
+
+// extend grows the bounds of a sparse grid to cover p.
+func (a *Automaton) extend(p Point) {
+	if a.empty() {
+		a.lo, a.hi = p, p
+		return
+	}
+	for d := 0; d < a.Dims; d++ {
+		if p[d] < a.lo[d] {
+			a.lo[d] = p[d]
+		}
+		if p[d] > a.hi[d] {
+			a.hi[d] = p[d]
+		}
+	}
+}
+
+// onEdge reports whether p lies on a face of a sparse grid's bounds, so
+// removing it may shrink them.
+func (a *Automaton) onEdge(p Point) bool {
+	for d := 0; d < a.Dims; d++ {
+		if p[d] == a.lo[d] || p[d] == a.hi[d] {
+			return true
+		}
+	}
+	return false
+}
+
+// rebound recomputes a sparse grid's bounds from its stored cells.
+func (a *Automaton) rebound() {
+	a.lo, a.hi = Point{}, Point{}
+	a.lo[0], a.hi[0] = 0, -1
+	for p := range a.cells {
+		a.extend(p)
+	}
+}
+
+// include reallocates a dense grid to cover the box lo..hi as well.
+func (a *Automaton) include(lo, hi Point) {
+	if !a.empty() {
+		for d := 0; d < a.Dims; d++ {
+			if a.lo[d] < lo[d] {
+				lo[d] = a.lo[d]
+			}
+			if a.hi[d] > hi[d] {
+				hi[d] = a.hi[d]
+			}
+		}
+	}
+
+	grid := newGrid(lo, hi, a.Dims, a.Background)
+	resized := &Automaton{Dims: a.Dims, dense: true, grid: grid, lo: lo, hi: hi}
+	a.each(func(p Point, state int) {
+		i, _ := resized.index(p)
+		grid[i] = state
+	})
+	a.grid, a.lo, a.hi = grid, lo, hi
+}
+
+func newGrid(lo, hi Point, dims, background int) []int {
+	size := 1
+	for d := 0; d < dims; d++ {
+		size *= hi[d] - lo[d] + 1
+	}
+	grid := make([]int, size)
+	if background != 0 {
+		for i := range grid {
+			grid[i] = background
+		}
+	}
+	return grid
+}
+
+// each visits the stored cells: all of a dense grid's box, in row-major
+// order, or a sparse grid's non-background cells in no order.
+func (a *Automaton) each(fn func(p Point, state int)) {
+	if !a.dense {
+		for p, s := range a.cells {
+			fn(p, s)
+		}
+		return
+	}
+	if a.empty() {
+		return
+	}
+	i := 0
+	eachIn(a.lo, a.hi, a.Dims, func(p Point) {
+		fn(p, a.grid[i])
+		i++
+	})
+}
+
+// eachIn visits the box lo..hi over the first dims axes in row-major order.
+func eachIn(lo, hi Point, dims int, fn func(Point)) {
+	for d := 0; d < dims; d++ {
+		if lo[d] > hi[d] {
+			return
+		}
+	}
+
+	p := lo
+	for {
+		fn(p)
+		d := dims - 1
+		for ; d >= 0; d-- {
+			if p[d] < hi[d] {
+				p[d]++
+				break
+			}
+			p[d] = lo[d]
+		}
+		if d < 0 {
+			return
+		}
+	}
+}
+
+// Count is how many cells are in state, or -1 if that is the state of an
+// infinite background.
+func (a *Automaton) Count(state int) int {
+	if state == a.Background && !(a.dense && a.Wrap) {
+		return -1
+	}
+	count := 0
+	a.each(func(_ Point, s int) {
+		if s == state {
+			count++
+		}
+	})
+	return count
+}
+
+func (a *Automaton) neighbour(p, o Point) int {
+	see := a.Neighbourhood.SeeThrough
+	if see == nil {
+		return a.Get(p.Add(o))
+	}
+
+	for q := a.wrap(p.Add(o)); q != p; q = a.wrap(q.Add(o)) {
+		i, ok := a.index(q)
+		if !ok {
+			break
+		}
+		if s := a.grid[i]; !see(s) {
+			return s
+		}
+	}
+	return a.Background
+}
+
+// Step advances every cell by one generation and returns how many changed,
+// counting a change of background as one.
+func (a *Automaton) Step() int {
+	if !a.dense && a.Neighbourhood.SeeThrough != nil {
+		panic("automaton: line of sight needs a dense grid")
+	}
+
+	offsets := a.Neighbourhood.Offsets
+	neighbours := make([]int, len(offsets))
+	next := func(p Point) int {
+		for i, o := range offsets {
+			neighbours[i] = a.neighbour(p, o)
+		}
+		return a.Rule(a.Get(p), neighbours)
+	}
+
+	changed := 0
+	background := a.Background
+	if !(a.dense && a.Wrap) {
+		for i := range neighbours {
+			neighbours[i] = a.Background
+		}
+		if background = a.Rule(a.Background, neighbours); background != a.Background {
+			changed++
+		}
+	}
+	change := func(from, to int) {
+		if from != to && !(from == a.Background && to == background) {
+			changed++
+		}
+	}
+
+	if a.dense {
+		lo, hi := a.lo, a.hi
+		if a.Grow && !a.Wrap && !a.empty() {
+			reach := a.Neighbourhood.Reach()
+			for d := 0; d < a.Dims; d++ {
+				lo[d], hi[d] = lo[d]-reach, hi[d]+reach
+			}
+		}
+
+		grid := newGrid(lo, hi, a.Dims, background)
+		i := 0
+		eachIn(lo, hi, a.Dims, func(p Point) {
+			grid[i] = next(p)
+			change(a.Get(p), grid[i])
+			i++
+		})
+		a.grid, a.lo, a.hi = grid, lo, hi
+	} else {
+		// Only cells that see a stored cell can differ from the background
+		candidates := make(map[Point]bool)
+		for p := range a.cells {
+			candidates[p] = true
+			for _, o := range offsets {
+				candidates[p.Add(o.negate())] = true
+			}
+		}
+
+		cells := make(map[Point]int)
+		for p := range candidates {
+			s := next(p)
+			change(a.Get(p), s)
+			if s != background {
+				cells[p] = s
+			}
+		}
+		a.cells = cells
+		a.rebound()
+	}
+
+	a.Background = background
+	a.Generation++
+	return changed
+}
+
+func (p Point) negate() Point {
+	for i := range p {
+		p[i] = -p[i]
+	}
+	return p
+}
+
+func (a *Automaton) Run(generations int) {
+	for i := 0; i < generations; i++ {
+		a.Step()
+	}
+}
+
+// RunUntilStable steps until a generation changes nothing, at most limit
+// times if limit is positive. It returns the steps taken, the unchanged one
+// included, and whether the grid settled.
+func (a *Automaton) RunUntilStable(limit int) (int, bool) {
+	for steps := 1; limit <= 0 || steps <= limit; steps++ {
+		if a.Step() == 0 {
+			return steps, true
+		}
+	}
+	return limit, false
+}
+
+// Format draws the stored box with one character per state, axis 0 as rows
+// and axis 1 as columns; higher dimensions print as a series of labelled
+// planes.
+func (a *Automaton) Format(states string) string {
+	if a.empty() {
+		return ""
+	}
+
+	var b strings.Builder
+	planeLo, planeHi := a.lo, a.hi
+	for d := 0; d < 2 && d < a.Dims; d++ {
+		planeLo[d], planeHi[d] = 0, 0
+	}
+	eachIn(planeLo, planeHi, a.Dims, func(plane Point) {
+		if a.Dims > 2 {
+			fmt.Fprintf(&b, "%v\n", plane[2:a.Dims])
+		}
+
+		lo, hi := a.lo, a.hi
+		for d := 2; d < a.Dims; d++ {
+			lo[d], hi[d] = plane[d], plane[d]
+		}
+		if a.Dims < 2 {
+			lo[1], hi[1] = 0, 0
+		}
+		eachIn(lo, hi, 2, func(p Point) {
+			b.WriteByte(states[a.Get(p)])
+			if p[1] == hi[1] {
+				b.WriteByte('\n')
+			}
+		})
+		if a.Dims > 2 {
+			b.WriteByte('\n')
+		}
+	})
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package automaton
+
+// Neighbourhood lists, as offsets from a cell, the cells whose states a rule
+// sees. Rules receive neighbour states in the order of Offsets.
+type Neighbourhood struct {
+	Offsets []Point
+
+	// SeeThrough, when set, makes each neighbour the first cell along its
+	// offset whose state it rejects, or the background past the grid's edge.
+	SeeThrough func(state int) bool
+}
+
+// Window is every offset within radius along each axis, the cell itself
+// included, in row-major order: axis 0 outermost, the last axis fastest.
+func Window(dims, radius int) Neighbourhood {
+	var lo, hi Point
+	for d := 0; d < dims; d++ {
+		lo[d], hi[d] = -radius, radius
+	}
+
+	offsets := make([]Point, 0)
+	eachIn(lo, hi, dims, func(p Point) {
+		offsets = append(offsets, p)
+	})
+	return Neighbourhood{Offsets: offsets}
+}
+
+// Moore is the 3^dims-1 cells touching a cell, even diagonally, in the
+// order of Window.
+func Moore(dims int) Neighbourhood {
+	return Window(dims, 1).filter(func(o Point) bool { return o.manhattan() != 0 })
+}
+
+// VonNeumann is the 2*dims cells sharing a face with a cell, in the order of
+// Window; in two dimensions that is up, left, right, down.
+func VonNeumann(dims int) Neighbourhood {
+	return Window(dims, 1).filter(func(o Point) bool { return o.manhattan() == 1 })
+}
+
+// Hex is the six neighbours of a hexagon in axial coordinates, axis 0
+// running east and axis 1 south-east: e, ne, nw, w, sw, se.
+func Hex() Neighbourhood {
+	return Neighbourhood{Offsets: []Point{
+		P(1, 0), P(1, -1), P(0, -1), P(-1, 0), P(-1, 1), P(0, 1),
+	}}
+}
+
+// LineOfSight looks past cells that seeThrough accepts, as a passenger sees
+// across floor to the nearest seat. It needs a dense grid.
+func (n Neighbourhood) LineOfSight(seeThrough func(state int) bool) Neighbourhood {
+	n.SeeThrough = seeThrough
+	return n
+}
+
+// Reach is the furthest any offset goes along one axis.
+func (n Neighbourhood) Reach() int {
+	reach := 0
+	for _, o := range n.Offsets {
+		for _, c := range o {
+			if c < 0 {
+				c = -c
+			}
+			if c > reach {
+				reach = c
+			}
+		}
+	}
+	return reach
+}
+
+func (n Neighbourhood) filter(keep func(Point) bool) Neighbourhood {
+	offsets := make([]Point, 0, len(n.Offsets))
+	for _, o := range n.Offsets {
+		if keep(o) {
+			offsets = append(offsets, o)
+		}
+	}
+	n.Offsets = offsets
+	return n
+}
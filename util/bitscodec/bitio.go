@@ -0,0 +1,64 @@
+package bitscodec
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrShortRead = errors.New("bitscodec: not enough bits")
+
+// Reader reads big-endian bit fields from a byte slice.
+type Reader struct {
+	data []byte
+	pos  int
+}
+
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+// Pos is the number of bits consumed so far.
+func (r *Reader) Pos() int { return r.pos }
+
+func (r *Reader) Remaining() int { return len(r.data)*8 - r.pos }
+
+func (r *Reader) ReadBits(n int) (int, error) {
+	if n < 0 || n > 62 {
+		return 0, fmt.Errorf("bitscodec: cannot read %d bits at once", n)
+	}
+	if n > r.Remaining() {
+		return 0, ErrShortRead
+	}
+
+	value := 0
+	for i := 0; i < n; i++ {
+		b := r.data[r.pos/8] >> (7 - r.pos%8) & 1
+		value = value<<1 | int(b)
+		r.pos++
+	}
+	return value, nil
+}
+
+// Writer appends big-endian bit fields to a growing byte slice.
+type Writer struct {
+	data []byte
+	n    int
+}
+
+// Len is the number of bits written so far.
+func (w *Writer) Len() int { return w.n }
+
+func (w *Writer) WriteBits(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		if value>>i&1 == 1 {
+			w.data[w.n/8] |= 1 << (7 - w.n%8)
+		}
+		w.n++
+	}
+}
+
+// Bytes returns the written bits, zero padded to a whole byte.
+func (w *Writer) Bytes() []byte { return w.data }
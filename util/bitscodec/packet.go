@@ -0,0 +1,301 @@
+package bitscodec
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	TypeSum = iota
+	TypeProduct
+	TypeMinimum
+	TypeMaximum
+	TypeLiteral
+	TypeGreater
+	TypeLess
+	TypeEqual
+)
+
+const (
+	LengthBits    = 0
+	LengthPackets = 1
+)
+
+type Packet struct {
+	Version int
+	Type    int
+
+	// Literal packets only
+	Literal int
+
+	// Operator packets only
+	LengthType int
+	Children   []*Packet
+}
+
+func (p *Packet) VersionSum() int {
+	sum := p.Version
+	for _, c := range p.Children {
+		sum += c.VersionSum()
+	}
+	return sum
+}
+
+func (p *Packet) Eval() (int, error) {
+	if p.Type == TypeLiteral {
+		return p.Literal, nil
+	}
+	if len(p.Children) == 0 {
+		return 0, fmt.Errorf("operator %s has no operands", opNames[p.Type])
+	}
+
+	values := make([]int, len(p.Children))
+	for i, c := range p.Children {
+		v, err := c.Eval()
+		if err != nil {
+			return 0, err
+		}
+		values[i] = v
+	}
+
+	switch p.Type {
+	case TypeSum:
+		sum := 0
+		for _, v := range values {
+			sum += v
+		}
+		return sum, nil
+	case TypeProduct:
+		product := 1
+		for _, v := range values {
+			product *= v
+		}
+		return product, nil
+	case TypeMinimum:
+		min := values[0]
+		for _, v := range values[1:] {
+			if min > v {
+				min = v
+			}
+		}
+		return min, nil
+	case TypeMaximum:
+		max := values[0]
+		for _, v := range values[1:] {
+			if max < v {
+				max = v
+			}
+		}
+		return max, nil
+	}
+
+	if len(values) != 2 {
+		return 0, fmt.Errorf("comparison %s needs 2 operands, got %d", opNames[p.Type], len(values))
+	}
+
+	var result bool
+	switch p.Type {
+	case TypeGreater:
+		result = values[0] > values[1]
+	case TypeLess:
+		result = values[0] < values[1]
+	case TypeEqual:
+		result = values[0] == values[1]
+	default:
+		return 0, fmt.Errorf("unknown type ID %d", p.Type)
+	}
+
+	if result {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func DecodeHex(s string) (*Packet, error) {
+	s = strings.TrimSpace(s)
+	if len(s)%2 == 1 {
+		s += "0"
+	}
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
+
+// Decode reads a single outermost packet; trailing padding is ignored.
+func Decode(data []byte) (*Packet, error) {
+	return ReadPacket(NewReader(data))
+}
+
+func ReadPacket(r *Reader) (*Packet, error) {
+	version, err := r.ReadBits(3)
+	if err != nil {
+		return nil, err
+	}
+	typeId, err := r.ReadBits(3)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Packet{Version: version, Type: typeId}
+	if typeId == TypeLiteral {
+		err = readLiteral(r, p)
+	} else {
+		err = readOperator(r, p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func readLiteral(r *Reader, p *Packet) error {
+	for groups := 0; ; groups++ {
+		if groups == 15 {
+			return fmt.Errorf("literal at bit %d overflows int", r.Pos())
+		}
+
+		group, err := r.ReadBits(5)
+		if err != nil {
+			return err
+		}
+		p.Literal = p.Literal<<4 | group&0xF
+		if group&0x10 == 0 {
+			return nil
+		}
+	}
+}
+
+func readOperator(r *Reader, p *Packet) error {
+	lengthType, err := r.ReadBits(1)
+	if err != nil {
+		return err
+	}
+	p.LengthType = lengthType
+
+	switch lengthType {
+	case LengthBits:
+		bitCount, err := r.ReadBits(15)
+		if err != nil {
+			return err
+		}
+		if bitCount > r.Remaining() {
+			return ErrShortRead
+		}
+
+		end := r.Pos() + bitCount
+		for r.Pos() < end {
+			child, err := ReadPacket(r)
+			if err != nil {
+				return err
+			}
+			p.Children = append(p.Children, child)
+		}
+		if r.Pos() != end {
+			return fmt.Errorf("subpackets overran declared length by %d bits", r.Pos()-end)
+		}
+	case LengthPackets:
+		packetCount, err := r.ReadBits(11)
+		if err != nil {
+			return err
+		}
+
+		p.Children = make([]*Packet, packetCount)
+		for i := range p.Children {
+			if p.Children[i], err = ReadPacket(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *Packet) Encode() ([]byte, error) {
+	var w Writer
+	if err := p.AppendTo(&w); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+
+func (p *Packet) EncodeHex() (string, error) {
+	data, err := p.Encode()
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(data)), nil
+}
+
+// AppendTo appends p's bits to w. It fails, leaving w part written, on any
+// field too big for its width: literals must be non-negative and below 2^60,
+// which is as many groups as Decode reads, and operators are limited to 2047
+// subpackets or 32767 subpacket bits by their length type.
+func (p *Packet) AppendTo(w *Writer) error {
+	if p.Version < 0 || p.Version > 7 {
+		return fmt.Errorf("version %d does not fit in 3 bits", p.Version)
+	}
+	if p.Type < 0 || p.Type > 7 {
+		return fmt.Errorf("type ID %d does not fit in 3 bits", p.Type)
+	}
+	w.WriteBits(p.Version, 3)
+	w.WriteBits(p.Type, 3)
+
+	if p.Type == TypeLiteral {
+		if p.Literal < 0 || p.Literal >= 1<<60 {
+			return fmt.Errorf("literal %d does not fit in 15 groups", p.Literal)
+		}
+		groups := 1
+		for groups < 15 && p.Literal>>(4*groups) != 0 {
+			groups++
+		}
+		for i := groups - 1; i >= 0; i-- {
+			group := p.Literal >> (4 * i) & 0xF
+			if i > 0 {
+				group |= 0x10
+			}
+			w.WriteBits(group, 5)
+		}
+		return nil
+	}
+
+	switch p.LengthType {
+	case LengthBits:
+		var sub Writer
+		for _, c := range p.Children {
+			if err := c.AppendTo(&sub); err != nil {
+				return err
+			}
+		}
+		if sub.Len() >= 1<<15 {
+			return fmt.Errorf("%d subpacket bits do not fit in 15 bits", sub.Len())
+		}
+		w.WriteBits(p.LengthType, 1)
+		w.WriteBits(sub.Len(), 15)
+		r := NewReader(sub.Bytes())
+		for r.Pos() < sub.Len() {
+			n := sub.Len() - r.Pos()
+			if n > 32 {
+				n = 32
+			}
+			bits, _ := r.ReadBits(n)
+			w.WriteBits(bits, n)
+		}
+	case LengthPackets:
+		if len(p.Children) >= 1<<11 {
+			return fmt.Errorf("%d subpackets do not fit in 11 bits", len(p.Children))
+		}
+		w.WriteBits(p.LengthType, 1)
+		w.WriteBits(len(p.Children), 11)
+		for _, c := range p.Children {
+			if err := c.AppendTo(w); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown length type %d", p.LengthType)
+	}
+	return nil
+}
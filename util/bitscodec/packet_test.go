@@ -0,0 +1,127 @@
+package bitscodec
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// randomPacket builds a packet tree no deeper than depth, with every field
+// in range.
+func randomPacket(rng *rand.Rand, depth int) *Packet {
+	p := &Packet{Version: rng.Intn(8), Type: rng.Intn(8)}
+	if p.Type == TypeLiteral || depth == 0 {
+		p.Type = TypeLiteral
+		p.Literal = int(rng.Int63n(1 << 60))
+		if rng.Intn(2) == 0 {
+			p.Literal >>= rng.Intn(60)
+		}
+		return p
+	}
+	p.LengthType = rng.Intn(2)
+	for i := rng.Intn(4); i >= 0; i-- {
+		p.Children = append(p.Children, randomPacket(rng, depth-1))
+	}
+	return p
+}
+
+func equal(a, b *Packet) bool {
+	if a.Version != b.Version || a.Type != b.Type || a.Literal != b.Literal ||
+		a.LengthType != b.LengthType || len(a.Children) != len(b.Children) {
+		return false
+	}
+	for i := range a.Children {
+		if !equal(a.Children[i], b.Children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(16))
+	for i := 0; i < 2000; i++ {
+		p := randomPacket(rng, 4)
+		data, err := p.Encode()
+		if err != nil {
+			t.Fatalf("%s: encode: %v", p, err)
+		}
+		q, err := Decode(data)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", p, err)
+		}
+		if !equal(p, q) {
+			t.Fatalf("decoded %s, want %s", q, p)
+		}
+		again, err := q.Encode()
+		if err != nil {
+			t.Fatalf("%s: encode again: %v", q, err)
+		}
+		if !bytes.Equal(data, again) {
+			t.Fatalf("%s: encoded %X, then %X", p, data, again)
+		}
+	}
+}
+
+func TestRoundTripHex(t *testing.T) {
+	for _, s := range []string{
+		"D2FE28",
+		"38006F45291200",
+		"EE00D40C823060",
+		"8A004A801A8002F478",
+		"620080001611562C8802118E34",
+		"C0015000016115A2E0802F182340",
+		"A0016C880162017C3686B18A3D4780",
+		"9C0141080250320F1802104A08",
+	} {
+		p, err := DecodeHex(s)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		h, err := p.EncodeHex()
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		q, err := DecodeHex(h)
+		if err != nil {
+			t.Fatalf("%s: re-encoded as %s: %v", s, h, err)
+		}
+		if h2, _ := q.EncodeHex(); h2 != h || !equal(p, q) {
+			t.Errorf("%s: re-encoded as %s, then %s", s, h, h2)
+		}
+	}
+}
+
+func TestEncodeOverflow(t *testing.T) {
+	lit := func(n int) *Packet { return &Packet{Type: TypeLiteral, Literal: n} }
+	many := make([]*Packet, 2048)
+	for i := range many {
+		many[i] = lit(0)
+	}
+	// Each 15-group literal is 81 bits, so 405 of them overrun 32767 bits
+	long := make([]*Packet, 405)
+	for i := range long {
+		long[i] = lit(1<<60 - 1)
+	}
+
+	for _, tc := range []struct {
+		name string
+		p    *Packet
+		ok   bool
+	}{
+		{"largest literal", lit(1<<60 - 1), true},
+		{"literal 2^60", lit(1 << 60), false},
+		{"negative literal", lit(-1), false},
+		{"version 8", &Packet{Version: 8, Type: TypeLiteral}, false},
+		{"2047 subpackets", &Packet{LengthType: LengthPackets, Children: many[:2047]}, true},
+		{"2048 subpackets", &Packet{LengthType: LengthPackets, Children: many}, false},
+		{"32724 subpacket bits", &Packet{LengthType: LengthBits, Children: long[:404]}, true},
+		{"32805 subpacket bits", &Packet{LengthType: LengthBits, Children: long}, false},
+		{"nested overflow", &Packet{LengthType: LengthPackets, Children: []*Packet{lit(1 << 62)}}, false},
+	} {
+		_, err := tc.p.Encode()
+		if ok := err == nil; ok != tc.ok {
+			t.Errorf("%s: got error %v", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package bitscodec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var opNames = map[int]string{
+	TypeSum:     "sum",
+	TypeProduct: "product",
+	TypeMinimum: "min",
+	TypeMaximum: "max",
+	TypeGreater: "gt",
+	TypeLess:    "lt",
+	TypeEqual:   "eq",
+}
+
+var opTypes = map[string]int{
+	"sum":     TypeSum,
+	"+":       TypeSum,
+	"product": TypeProduct,
+	"*":       TypeProduct,
+	"min":     TypeMinimum,
+	"max":     TypeMaximum,
+	"gt":      TypeGreater,
+	">":       TypeGreater,
+	"lt":      TypeLess,
+	"<":       TypeLess,
+	"eq":      TypeEqual,
+	"=":       TypeEqual,
+}
+
+// head renders a literal value or operator name, with a "@version" suffix
+// when the version is non-zero.
+func (p *Packet) head() string {
+	var s string
+	if p.Type == TypeLiteral {
+		s = strconv.Itoa(p.Literal)
+	} else if name, found := opNames[p.Type]; found {
+		s = name
+	} else {
+		s = fmt.Sprintf("type%d", p.Type)
+	}
+
+	if p.Version != 0 {
+		s += "@" + strconv.Itoa(p.Version)
+	}
+	return s
+}
+
+// String renders p as a one-line S-expression, e.g. "(sum@2 1 (max 3 4))".
+func (p *Packet) String() string {
+	if p.Type == TypeLiteral {
+		return p.head()
+	}
+
+	var b strings.Builder
+	b.WriteString("(" + p.head())
+	for _, c := range p.Children {
+		b.WriteString(" " + c.String())
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// Pretty renders p as an S-expression with one operand per line.
+func (p *Packet) Pretty() string {
+	var b strings.Builder
+	p.pretty(&b, 0)
+	return b.String()
+}
+
+func (p *Packet) pretty(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	if p.Type == TypeLiteral || len(p.Children) == 0 {
+		b.WriteString(p.String())
+		return
+	}
+
+	b.WriteString("(" + p.head())
+	for _, c := range p.Children {
+		b.WriteString("\n")
+		c.pretty(b, depth+1)
+	}
+	b.WriteString(")")
+}
+
+// ParseExpr reads an S-expression as written by String or Pretty. Operators
+// may also be spelled + * > < =. Parsed operators use the packet-count
+// length type.
+func ParseExpr(s string) (*Packet, error) {
+	tokens := tokenize(s)
+	p, rest, err := parseTokens(tokens)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("unexpected %q after expression", rest[0])
+	}
+	return p, nil
+}
+
+func tokenize(s string) []string {
+	s = strings.ReplaceAll(s, "(", " ( ")
+	s = strings.ReplaceAll(s, ")", " ) ")
+	return strings.FieldsFunc(s, unicode.IsSpace)
+}
+
+func parseTokens(tokens []string) (*Packet, []string, error) {
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	switch tokens[0] {
+	case ")":
+		return nil, nil, fmt.Errorf("unexpected ')'")
+	case "(":
+		if len(tokens) < 2 {
+			return nil, nil, fmt.Errorf("unexpected end of expression")
+		}
+
+		name, version, err := splitVersion(tokens[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		typeId, found := opTypes[name]
+		if !found {
+			return nil, nil, fmt.Errorf("unknown operator %q", name)
+		}
+
+		p := &Packet{Version: version, Type: typeId, LengthType: LengthPackets}
+		tokens = tokens[2:]
+		for len(tokens) > 0 && tokens[0] != ")" {
+			var child *Packet
+			if child, tokens, err = parseTokens(tokens); err != nil {
+				return nil, nil, err
+			}
+			p.Children = append(p.Children, child)
+		}
+		if len(tokens) == 0 {
+			return nil, nil, fmt.Errorf("missing ')' for %q", name)
+		}
+		return p, tokens[1:], nil
+	default:
+		value, version, err := splitVersion(tokens[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		literal, err := strconv.Atoi(value)
+		if err != nil || literal < 0 {
+			return nil, nil, fmt.Errorf("bad literal %q", tokens[0])
+		}
+		return &Packet{Version: version, Type: TypeLiteral, Literal: literal}, tokens[1:], nil
+	}
+}
+
+func splitVersion(token string) (string, int, error) {
+	before, after, found := strings.Cut(token, "@")
+	if !found {
+		return token, 0, nil
+	}
+	version, err := strconv.Atoi(after)
+	if err != nil || version < 0 || version > 7 {
+		return "", 0, fmt.Errorf("bad version in %q", token)
+	}
+	return before, version, nil
+}
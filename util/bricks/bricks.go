@@ -0,0 +1,138 @@
+package bricks
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Box is the cubes from Min to Max, both included.
+type Box struct {
+	Min, Max [3]int
+}
+
+func ParseBox(line string) (Box, error) {
+	var b Box
+	if _, err := fmt.Sscanf(line, "%d,%d,%d~%d,%d,%d",
+		&b.Min[0], &b.Min[1], &b.Min[2], &b.Max[0], &b.Max[1], &b.Max[2]); err != nil {
+		return b, fmt.Errorf("bricks: bad brick %q: %w", line, err)
+	}
+	for i := range b.Min {
+		if b.Min[i] > b.Max[i] {
+			b.Min[i], b.Max[i] = b.Max[i], b.Min[i]
+		}
+	}
+	return b, nil
+}
+
+func Parse(input []string) ([]Box, error) {
+	boxes := make([]Box, 0, len(input))
+	for _, line := range input {
+		if line == "" {
+			continue
+		}
+		b, err := ParseBox(line)
+		if err != nil {
+			return nil, err
+		}
+		if b.Min[2] < 1 {
+			return nil, fmt.Errorf("bricks: brick %q is below the ground", line)
+		}
+		boxes = append(boxes, b)
+	}
+	return boxes, nil
+}
+
+func (b Box) String() string {
+	return fmt.Sprintf("%d,%d,%d~%d,%d,%d", b.Min[0], b.Min[1], b.Min[2], b.Max[0], b.Max[1], b.Max[2])
+}
+
+// Stack is bricks after they have all fallen as far as they can. Bricks keep
+// their input order; Below lists the bricks each one rests on, and Above
+// those resting on it.
+type Stack struct {
+	Bricks       []Box
+	Below, Above [][]int
+	order        []int // bricks from the ground up, each after all below it
+}
+
+// Settle drops bricks, lowest first, until each rests on the ground at z 1
+// or on another brick.
+func Settle(boxes []Box) *Stack {
+	n := len(boxes)
+	s := &Stack{Bricks: append([]Box{}, boxes...), Below: make([][]int, n), Above: make([][]int, n), order: make([]int, n)}
+	for i := range s.order {
+		s.order[i] = i
+	}
+	sort.SliceStable(s.order, func(i, j int) bool { return boxes[s.order[i]].Min[2] < boxes[s.order[j]].Min[2] })
+	if n == 0 {
+		return s
+	}
+
+	// The top of the pile over each column, and the brick making it
+	type top struct{ z, brick int }
+	lo, hi := boxes[0].Min, boxes[0].Max
+	for _, b := range boxes {
+		for i := 0; i < 2; i++ {
+			if b.Min[i] < lo[i] {
+				lo[i] = b.Min[i]
+			}
+			if b.Max[i] > hi[i] {
+				hi[i] = b.Max[i]
+			}
+		}
+	}
+	width := hi[0] - lo[0] + 1
+	tops := make([]top, width*(hi[1]-lo[1]+1))
+	for i := range tops {
+		tops[i].brick = -1
+	}
+	columns := func(b Box, visit func(col int)) {
+		for y := b.Min[1]; y <= b.Max[1]; y++ {
+			for x := b.Min[0]; x <= b.Max[0]; x++ {
+				visit((y-lo[1])*width + x - lo[0])
+			}
+		}
+	}
+
+	for _, i := range s.order {
+		b := &s.Bricks[i]
+		rest := 0
+		columns(*b, func(col int) {
+			if tops[col].z > rest {
+				rest = tops[col].z
+			}
+		})
+		b.Max[2] -= b.Min[2] - rest - 1
+		b.Min[2] = rest + 1
+
+		columns(*b, func(col int) {
+			t := tops[col]
+			if t.brick >= 0 && t.z == rest && !contains(s.Below[i], t.brick) {
+				s.Below[i] = append(s.Below[i], t.brick)
+				s.Above[t.brick] = append(s.Above[t.brick], i)
+			}
+			tops[col] = top{b.Max[2], i}
+		})
+	}
+	return s
+}
+
+func contains(list []int, v int) bool {
+	for _, w := range list {
+		if w == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Safe reports whether removing brick i leaves every brick where it is:
+// each brick on it rests on another as well.
+func (s *Stack) Safe(i int) bool {
+	for _, j := range s.Above[i] {
+		if len(s.Below[j]) < 2 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package bricks
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var sample = []string{
+	"1,0,1~1,2,1",
+	"0,0,2~2,0,2",
+	"0,2,3~2,2,3",
+	"0,0,4~0,2,4",
+	"2,0,5~2,2,5",
+	"0,1,6~2,1,6",
+	"1,1,8~1,1,9",
+}
+
+func TestSample(t *testing.T) {
+	boxes, err := Parse(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Settle(boxes)
+
+	safe, falls := 0, 0
+	for i := range s.Bricks {
+		if s.Safe(i) {
+			safe++
+		}
+	}
+	for _, f := range s.Falls() {
+		falls += f
+	}
+	if safe != 5 || falls != 7 {
+		t.Errorf("%d safe and %d falls, want 5 and 7", safe, falls)
+	}
+}
+
+// chain removes brick i from a settled stack, settles the rest again, and
+// counts the bricks that moved.
+func chain(s *Stack, i int) int {
+	rest := make([]Box, 0, len(s.Bricks)-1)
+	rest = append(rest, s.Bricks[:i]...)
+	rest = append(rest, s.Bricks[i+1:]...)
+	moved := 0
+	for j, b := range Settle(rest).Bricks {
+		if b != rest[j] {
+			moved++
+		}
+	}
+	return moved
+}
+
+func randomBox(rng *rand.Rand) Box {
+	var b Box
+	for i := range b.Min {
+		b.Min[i] = rng.Intn(4)
+	}
+	b.Min[2] += 1 + rng.Intn(20)
+	b.Max = b.Min
+	b.Max[rng.Intn(3)] += rng.Intn(3)
+	return b
+}
+
+func TestFallsMatchesChain(t *testing.T) {
+	rng := rand.New(rand.NewSource(22))
+	total := 0
+	for round := 0; round < 200; round++ {
+		boxes := make([]Box, 1+rng.Intn(20))
+		for i := range boxes {
+			boxes[i] = randomBox(rng)
+		}
+		s := Settle(boxes)
+
+		for i, got := range s.Falls() {
+			total += got
+			if want := chain(s, i); got != want {
+				t.Fatalf("round %d: removing %v drops %d, Falls gives %d", round, s.Bricks[i], want, got)
+			}
+		}
+	}
+	if total == 0 {
+		t.Errorf("no brick brought down any other")
+	}
+}
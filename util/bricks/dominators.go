@@ -0,0 +1,86 @@
+package bricks
+
+// Ground stands for the ground in Dominators.
+const Ground = -1
+
+// Dominators finds, for each brick, the nearest brick that holds it up
+// alone: every way down from it to the ground passes through that brick, so
+// removing it makes this one fall too. It is Ground for bricks that no
+// single brick holds up.
+//
+// The bricks below another all settled before it, so in settling order each
+// brick's dominator is the lowest common ancestor of its supports' in the
+// tree built so far. Ancestors are found by binary lifting, which makes the
+// whole search O(n log n).
+func (s *Stack) Dominators() []int {
+	n := len(s.Bricks)
+	levels := 1
+	for 1<<levels <= n {
+		levels++
+	}
+
+	// Nodes are the bricks, then the ground as n
+	depth := make([]int, n+1)
+	up := make([][]int, levels)
+	for k := range up {
+		up[k] = make([]int, n+1)
+		up[k][n] = n
+	}
+	lca := func(a, b int) int {
+		if depth[a] < depth[b] {
+			a, b = b, a
+		}
+		for k := levels - 1; k >= 0; k-- {
+			if depth[a]-1<<k >= depth[b] {
+				a = up[k][a]
+			}
+		}
+		if a == b {
+			return a
+		}
+		for k := levels - 1; k >= 0; k-- {
+			if up[k][a] != up[k][b] {
+				a, b = up[k][a], up[k][b]
+			}
+		}
+		return up[0][a]
+	}
+
+	for _, i := range s.order {
+		dom := n
+		for j, below := range s.Below[i] {
+			if j == 0 {
+				dom = below
+			} else {
+				dom = lca(dom, below)
+			}
+		}
+		depth[i] = depth[dom] + 1
+		up[0][i] = dom
+		for k := 1; k < levels; k++ {
+			up[k][i] = up[k-1][up[k-1][i]]
+		}
+	}
+
+	doms := make([]int, n)
+	for i := range doms {
+		if doms[i] = up[0][i]; doms[i] == n {
+			doms[i] = Ground
+		}
+	}
+	return doms
+}
+
+// Falls counts, for each brick, how many others fall if it is removed: those
+// it dominates.
+func (s *Stack) Falls() []int {
+	doms := s.Dominators()
+	falls := make([]int, len(s.Bricks))
+	for k := len(s.order) - 1; k >= 0; k-- {
+		i := s.order[k]
+		if d := doms[i]; d != Ground {
+			falls[d] += falls[i] + 1
+		}
+	}
+	return falls
+}
@@ -0,0 +1,109 @@
+package bricks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// label names brick i by letter, as the puzzle does, while there are few
+// enough bricks for that.
+func (s *Stack) label(i int) byte {
+	if len(s.Bricks) <= 26 {
+		return byte('A' + i)
+	}
+	return '#'
+}
+
+func (s *Stack) bounds() (lo, hi [3]int) {
+	for i, b := range s.Bricks {
+		for k := range lo {
+			if i == 0 || b.Min[k] < lo[k] {
+				lo[k] = b.Min[k]
+			}
+			if i == 0 || b.Max[k] > hi[k] {
+				hi[k] = b.Max[k]
+			}
+		}
+	}
+	return lo, hi
+}
+
+// Boxes lists the settled bricks one per line, in the input's format.
+func (s *Stack) Boxes() string {
+	var sb strings.Builder
+	for _, b := range s.Bricks {
+		sb.WriteString(b.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// View draws the stack the way the puzzle does, looking along y for axis 0
+// or along x for axis 1. Where several bricks line up, it shows '?'.
+func (s *Stack) View(axis int) string {
+	if len(s.Bricks) == 0 {
+		return ""
+	}
+	lo, hi := s.bounds()
+	width := hi[axis] - lo[axis] + 1
+	rows := make([][]byte, hi[2]+1)
+	for z := range rows {
+		rows[z] = []byte(strings.Repeat(".", width))
+	}
+	for i, b := range s.Bricks {
+		for z := b.Min[2]; z <= b.Max[2]; z++ {
+			for a := b.Min[axis]; a <= b.Max[axis]; a++ {
+				if c := &rows[z][a-lo[axis]]; *c == '.' {
+					*c = s.label(i)
+				} else {
+					*c = '?'
+				}
+			}
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%*c\n", (width+1)/2, "xy"[axis])
+	for a := lo[axis]; a <= hi[axis]; a++ {
+		fmt.Fprint(&sb, a%10)
+	}
+	sb.WriteByte('\n')
+	for z := hi[2]; z >= 1; z-- {
+		fmt.Fprintf(&sb, "%s %d\n", rows[z], z)
+	}
+	fmt.Fprintf(&sb, "%s 0\n", strings.Repeat("-", width))
+	return sb.String()
+}
+
+// Slices draws the stack one level at a time from the ground up, each level
+// seen from above with x across and y down.
+func (s *Stack) Slices() string {
+	if len(s.Bricks) == 0 {
+		return ""
+	}
+	lo, hi := s.bounds()
+	width, depth := hi[0]-lo[0]+1, hi[1]-lo[1]+1
+	levels := make([][]byte, hi[2]+1)
+	for z := range levels {
+		levels[z] = []byte(strings.Repeat(".", width*depth))
+	}
+	for i, b := range s.Bricks {
+		for z := b.Min[2]; z <= b.Max[2]; z++ {
+			for y := b.Min[1]; y <= b.Max[1]; y++ {
+				for x := b.Min[0]; x <= b.Max[0]; x++ {
+					levels[z][(y-lo[1])*width+x-lo[0]] = s.label(i)
+				}
+			}
+		}
+	}
+
+	var sb strings.Builder
+	for z := 1; z <= hi[2]; z++ {
+		fmt.Fprintf(&sb, "z=%d\n", z)
+		for y := 0; y < depth; y++ {
+			sb.Write(levels[z][y*width : (y+1)*width])
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
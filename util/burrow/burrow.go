@@ -0,0 +1,203 @@
+package burrow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Burrow is the shape of a drawing: a hallway along its first open row, and
+// rooms hanging below it, one per column, meant for A, B, C... from left to
+// right. Any hallway length, room count and room depth will do.
+type Burrow struct {
+	template []string // the drawing with every open cell emptied
+	cells    []pos    // open cells, hallway first
+	index    map[pos]int
+
+	stops  []int   // hallway cells an amphipod may stop on
+	rooms  [][]int // cells of each room, top to bottom
+	roomOf []int   // room of each cell, or -1 in the hallway
+	depth  []int   // depth of each cell in its room, from 0 at the top
+
+	dist  [][]int
+	route [][][]int // cells passed from one cell to another, the last included
+}
+
+type pos struct {
+	row, col int
+}
+
+// State has one byte per open cell: '.' or the amphipod standing there.
+type State string
+
+// Parse reads a burrow drawing, returning its shape and the amphipods in it.
+func Parse(input []string) (*Burrow, State, error) {
+	b := &Burrow{template: make([]string, len(input)), index: make(map[pos]int)}
+	hallway := -1
+	start := make([]byte, 0)
+	rooms := make(map[int][]int)
+	cols := make([]int, 0)
+	for r, line := range input {
+		row := []byte(line)
+		for c, ch := range row {
+			switch {
+			case ch == '#' || ch == ' ':
+				continue
+			case ch != '.' && (ch < 'A' || ch > 'Z'):
+				return nil, "", fmt.Errorf("burrow: line %d %q: unknown cell %q", r+1, line, ch)
+			}
+
+			if hallway < 0 {
+				hallway = r
+			}
+			p := pos{r, c}
+			b.index[p] = len(b.cells)
+			b.cells = append(b.cells, p)
+			start = append(start, ch)
+			row[c] = '.'
+
+			if r > hallway {
+				if _, ok := rooms[c]; !ok {
+					cols = append(cols, c)
+				}
+				rooms[c] = append(rooms[c], b.index[p])
+			}
+		}
+		b.template[r] = string(row)
+	}
+	if hallway < 0 {
+		return nil, "", fmt.Errorf("burrow: no open cells")
+	}
+
+	sort.Ints(cols)
+
+	b.roomOf = make([]int, len(b.cells))
+	b.depth = make([]int, len(b.cells))
+	for i := range b.roomOf {
+		b.roomOf[i] = -1
+	}
+	doors := make(map[int]bool)
+	for room, c := range cols {
+		cells := rooms[c]
+		for d, i := range cells {
+			if b.cells[i].row != hallway+1+d {
+				return nil, "", fmt.Errorf("burrow: room %c has a gap", 'A'+room)
+			}
+			b.roomOf[i], b.depth[i] = room, d
+		}
+		door, ok := b.index[pos{hallway, c}]
+		if !ok {
+			return nil, "", fmt.Errorf("burrow: room %c does not open onto the hallway", 'A'+room)
+		}
+		doors[door] = true
+		b.rooms = append(b.rooms, cells)
+	}
+	for i, p := range b.cells {
+		if p.row == hallway && !doors[i] {
+			b.stops = append(b.stops, i)
+		}
+	}
+
+	if err := b.check(State(start)); err != nil {
+		return nil, "", err
+	}
+	b.routes()
+	return b, State(start), nil
+}
+
+// check makes sure every amphipod has a room, and every room exactly enough
+// amphipods to fill it.
+func (b *Burrow) check(s State) error {
+	counts := make(map[byte]int)
+	for i := 0; i < len(s); i++ {
+		if s[i] != '.' {
+			counts[s[i]]++
+		}
+	}
+	for room, cells := range b.rooms {
+		kind := byte('A' + room)
+		if counts[kind] != len(cells) {
+			return fmt.Errorf("burrow: %d amphipods of type %c for a room of %d", counts[kind], kind, len(cells))
+		}
+		delete(counts, kind)
+	}
+	for kind := range counts {
+		return fmt.Errorf("burrow: no room for amphipods of type %c", kind)
+	}
+	return nil
+}
+
+// routes finds the shortest way between every pair of cells. Each cell
+// touches at most four others, so a search from every cell is cheap.
+func (b *Burrow) routes() {
+	n := len(b.cells)
+	b.dist, b.route = make([][]int, n), make([][][]int, n)
+	for from := range b.cells {
+		prev := make([]int, n)
+		for i := range prev {
+			prev[i] = -1
+		}
+		prev[from] = from
+		b.dist[from] = make([]int, n)
+
+		queue := []int{from}
+		for len(queue) > 0 {
+			i := queue[0]
+			queue = queue[1:]
+			p := b.cells[i]
+			for _, q := range []pos{{p.row - 1, p.col}, {p.row, p.col - 1}, {p.row, p.col + 1}, {p.row + 1, p.col}} {
+				if j, ok := b.index[q]; ok && prev[j] < 0 {
+					prev[j], b.dist[from][j] = i, b.dist[from][i]+1
+					queue = append(queue, j)
+				}
+			}
+		}
+
+		b.route[from] = make([][]int, n)
+		for to := range b.cells {
+			route := make([]int, b.dist[from][to])
+			for i, j := len(route)-1, to; i >= 0; i, j = i-1, prev[j] {
+				route[i] = j
+			}
+			b.route[from][to] = route
+		}
+	}
+}
+
+// Energy is what one step costs an amphipod: 1 for A, 10 for B, and so on.
+func Energy(kind byte) int {
+	e := 1
+	for k := byte('A'); k < kind; k++ {
+		e *= 10
+	}
+	return e
+}
+
+// Goal is the state with every amphipod home.
+func (b *Burrow) Goal() State {
+	goal := []byte(strings.Repeat(".", len(b.cells)))
+	for room, cells := range b.rooms {
+		for _, i := range cells {
+			goal[i] = byte('A' + room)
+		}
+	}
+	return State(goal)
+}
+
+// Format draws s the way the input drew the burrow.
+func (b *Burrow) Format(s State) string {
+	rows := make([][]byte, len(b.template))
+	for r, line := range b.template {
+		rows[r] = []byte(line)
+	}
+	for i, p := range b.cells {
+		rows[p.row][p.col] = s[i]
+	}
+
+	var sb strings.Builder
+	for _, row := range rows {
+		sb.Write(row)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,220 @@
+package burrow
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+)
+
+var ErrStuck = errors.New("burrow: the amphipods cannot all get home")
+
+// Move takes the amphipod on cell From to cell To, without stopping.
+type Move struct {
+	Kind     byte
+	From, To int
+	Steps    int
+}
+
+func (m Move) Energy() int { return m.Steps * Energy(m.Kind) }
+
+func (m Move) String() string {
+	return fmt.Sprintf("%c moves %d steps for %d energy", m.Kind, m.Steps, m.Energy())
+}
+
+// settled reports whether the amphipod on cell i is home with only its own
+// kind below it, so it never has to move again.
+func (b *Burrow) settled(s State, i int) bool {
+	room := b.roomOf[i]
+	if room < 0 || s[i] != byte('A'+room) {
+		return false
+	}
+	for _, j := range b.rooms[room][b.depth[i]:] {
+		if s[j] != s[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// entry is the deepest free cell of a kind's room, or -1 while a stranger is
+// still inside.
+func (b *Burrow) entry(s State, kind byte) int {
+	cells := b.rooms[kind-'A']
+	for d := len(cells) - 1; d >= 0; d-- {
+		switch s[cells[d]] {
+		case '.':
+			return cells[d]
+		case kind:
+		default:
+			return -1
+		}
+	}
+	return -1
+}
+
+func (b *Burrow) clear(s State, route []int) bool {
+	for _, i := range route {
+		if s[i] != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// Moves lists what the amphipods may do from s. An amphipod in the hallway
+// only moves into its own room, once no stranger is left there; one in a room
+// moves out to the hallway, or straight home.
+func (b *Burrow) Moves(s State) []Move {
+	moves := make([]Move, 0)
+	for i := 0; i < len(s); i++ {
+		kind := s[i]
+		if kind == '.' || b.settled(s, i) {
+			continue
+		}
+
+		targets := make([]int, 0, len(b.stops)+1)
+		if home := b.entry(s, kind); home >= 0 {
+			targets = append(targets, home)
+		}
+		if b.roomOf[i] >= 0 {
+			targets = append(targets, b.stops...)
+		}
+		for _, t := range targets {
+			if b.clear(s, b.route[i][t]) {
+				moves = append(moves, Move{kind, i, t, b.dist[i][t]})
+			}
+		}
+	}
+	return moves
+}
+
+func (s State) Apply(m Move) State {
+	next := []byte(s)
+	next[m.From], next[m.To] = '.', m.Kind
+	return State(next)
+}
+
+// Estimate is a lower bound on the energy left to spend from s. Every
+// amphipod not yet settled has to walk to the top of its room, leaving first
+// if it is already there; and those entering a room fill it to different
+// depths, so at least 0, 1, 2... steps further down.
+func (b *Burrow) Estimate(s State) int {
+	estimate := 0
+	entering := make([]int, len(b.rooms))
+	for i := 0; i < len(s); i++ {
+		kind := s[i]
+		if kind == '.' || b.settled(s, i) {
+			continue
+		}
+
+		room := int(kind - 'A')
+		steps := b.dist[i][b.rooms[room][0]]
+		if b.roomOf[i] == room {
+			// Out through the door, a step aside and back, then in again
+			steps = b.depth[i] + 4
+		}
+		estimate += steps * Energy(kind)
+		entering[room]++
+	}
+	for room, k := range entering {
+		estimate += k * (k - 1) / 2 * Energy(byte('A'+room))
+	}
+	return estimate
+}
+
+// Solution is the cheapest way home: States runs from the start to the goal,
+// with Moves[i] taking States[i] to States[i+1].
+type Solution struct {
+	Energy   int
+	States   []State
+	Moves    []Move
+	Explored int // states taken off the queue
+}
+
+type node struct {
+	state    State
+	energy   int
+	estimate int
+	index    int
+}
+
+type nodeQueue []*node
+
+func (q nodeQueue) Len() int { return len(q) }
+func (q nodeQueue) Less(i, j int) bool {
+	return q[i].energy+q[i].estimate < q[j].energy+q[j].estimate
+}
+func (q nodeQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index, q[j].index = i, j
+}
+func (q *nodeQueue) Push(x interface{}) {
+	n := x.(*node)
+	n.index = len(*q)
+	*q = append(*q, n)
+}
+func (q *nodeQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	old[len(old)-1] = nil
+	n.index = -1
+	*q = old[:len(old)-1]
+	return n
+}
+
+// Solve runs A* from start, guided by Estimate, which never overshoots, so
+// the first time the goal comes off the queue its energy is the least.
+func (b *Burrow) Solve(start State) (*Solution, error) {
+	goal := b.Goal()
+	nodes := map[State]*node{start: {state: start, estimate: b.Estimate(start)}}
+	from := make(map[State]Move)
+	done := make(map[State]bool)
+
+	q := nodeQueue{nodes[start]}
+	explored := 0
+	for len(q) > 0 {
+		curr := heap.Pop(&q).(*node)
+		done[curr.state] = true
+		explored++
+		if curr.state == goal {
+			return b.trace(start, goal, curr.energy, from, explored), nil
+		}
+
+		for _, m := range b.Moves(curr.state) {
+			next := curr.state.Apply(m)
+			if done[next] {
+				continue
+			}
+			energy := curr.energy + m.Energy()
+			if n, ok := nodes[next]; !ok {
+				n = &node{state: next, energy: energy, estimate: b.Estimate(next)}
+				nodes[next] = n
+				heap.Push(&q, n)
+			} else if energy < n.energy {
+				n.energy = energy
+				heap.Fix(&q, n.index)
+			} else {
+				continue
+			}
+			from[next] = m
+		}
+	}
+	return nil, ErrStuck
+}
+
+func (b *Burrow) trace(start, goal State, energy int, from map[State]Move, explored int) *Solution {
+	sol := &Solution{Energy: energy, States: []State{goal}, Explored: explored}
+	for s := goal; s != start; {
+		m := from[s]
+		sol.Moves = append(sol.Moves, m)
+		s = s.Apply(Move{m.Kind, m.To, m.From, m.Steps})
+		sol.States = append(sol.States, s)
+	}
+	for i, j := 0, len(sol.States)-1; i < j; i, j = i+1, j-1 {
+		sol.States[i], sol.States[j] = sol.States[j], sol.States[i]
+	}
+	for i, j := 0, len(sol.Moves)-1; i < j; i, j = i+1, j-1 {
+		sol.Moves[i], sol.Moves[j] = sol.Moves[j], sol.Moves[i]
+	}
+	return sol
+}
@@ -0,0 +1,227 @@
+package cards
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxHand is the most cards a hand may hold.
+const MaxHand = 7
+
+type Kind int
+
+const (
+	HighCard Kind = iota
+	OnePair
+	TwoPair
+	ThreeOfAKind
+	FullHouse
+	FourOfAKind
+	FiveOfAKind
+)
+
+var kindNames = [...]string{
+	"high card",
+	"one pair",
+	"two pair",
+	"three of a kind",
+	"full house",
+	"four of a kind",
+	"five of a kind",
+}
+
+func (k Kind) String() string { return kindNames[k] }
+
+// Rules set the strength of each card label and which labels are wild. Wild
+// cards stand in for whatever makes the strongest kind, but break ties by
+// their own place in the order.
+type Rules struct {
+	Order string // weakest first
+	Wild  string
+
+	rank [256]uint8 // place in Order + 1, or 0 for bytes that are not cards
+	wild [256]bool
+}
+
+func NewRules(order, wild string) (*Rules, error) {
+	if len(order) > 255 {
+		return nil, fmt.Errorf("cards: %d labels is too many", len(order))
+	}
+	r := &Rules{Order: order, Wild: wild}
+	for i := 0; i < len(order); i++ {
+		if r.rank[order[i]] != 0 {
+			return nil, fmt.Errorf("cards: label %q repeated", order[i])
+		}
+		r.rank[order[i]] = uint8(i + 1)
+	}
+	for i := 0; i < len(wild); i++ {
+		if r.rank[wild[i]] == 0 {
+			return nil, fmt.Errorf("cards: wild card %q is not a label", wild[i])
+		}
+		r.wild[wild[i]] = true
+	}
+	return r, nil
+}
+
+func MustRules(order, wild string) *Rules {
+	r, err := NewRules(order, wild)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+var (
+	// Camel is Camel Cards as first explained.
+	Camel = MustRules("23456789TJQKA", "")
+	// Jokers makes J a wild joker, weaker than any other card.
+	Jokers = MustRules("J23456789TQKA", "J")
+)
+
+// Hand is an evaluated hand. Hands compare by kind, then card by card in the
+// order dealt.
+type Hand struct {
+	Cards string
+	Kind  Kind
+	As    byte // the label wild cards stand in for, or 0 if there are none
+
+	wildAt uint8 // bit i set if card i is wild
+	key    uint64
+}
+
+// Evaluate classifies cards. It does not allocate, so ranking millions of
+// hands costs little more than sorting them.
+func (r *Rules) Evaluate(cards string) (Hand, error) {
+	h := Hand{Cards: cards}
+	if len(cards) == 0 || len(cards) > MaxHand {
+		return h, fmt.Errorf("cards: hand %q must hold 1 to %d cards", cards, MaxHand)
+	}
+
+	var counts [256]uint8
+	wilds := 0
+	for i := 0; i < len(cards); i++ {
+		c := cards[i]
+		if r.rank[c] == 0 {
+			return h, fmt.Errorf("cards: %q in hand %q is not a card", c, cards)
+		}
+		h.key = h.key<<8 | uint64(r.rank[c])
+		if r.wild[c] {
+			h.wildAt |= 1 << i
+			wilds++
+		} else {
+			counts[c]++
+		}
+	}
+
+	// The two largest groups decide the kind; wild cards join the largest,
+	// and among equally large ones the strongest
+	var first, second uint8
+	for i := 0; i < len(cards); i++ {
+		c := cards[i]
+		n := counts[c]
+		switch {
+		case r.wild[c]:
+			continue
+		case c == h.As:
+			continue
+		case n > first || (n == first && r.rank[c] > r.rank[h.As]):
+			if h.As != 0 && counts[h.As] > second {
+				second = counts[h.As]
+			}
+			first, h.As = n, c
+		case n > second:
+			second = n
+		}
+	}
+	if wilds > 0 && h.As == 0 {
+		// All wild: the best five of a kind there is
+		h.As = r.Order[len(r.Order)-1]
+	}
+	if wilds == 0 {
+		h.As = 0
+	}
+
+	h.Kind = kind(int(first)+wilds, int(second))
+	h.key |= uint64(h.Kind) << 56
+	return h, nil
+}
+
+func kind(first, second int) Kind {
+	switch {
+	case first >= 5:
+		return FiveOfAKind
+	case first == 4:
+		return FourOfAKind
+	case first == 3 && second >= 2:
+		return FullHouse
+	case first == 3:
+		return ThreeOfAKind
+	case first == 2 && second == 2:
+		return TwoPair
+	case first == 2:
+		return OnePair
+	default:
+		return HighCard
+	}
+}
+
+func (r *Rules) MustEvaluate(cards string) Hand {
+	h, err := r.Evaluate(cards)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// Compare returns -1, 0 or 1 as a is weaker than, ties or beats b. Both must
+// be evaluated under the same rules.
+func Compare(a, b Hand) int {
+	switch {
+	case a.key < b.key:
+		return -1
+	case a.key > b.key:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Explain describes the hand's kind and how wild cards made it, as in
+// "full house via J as K".
+func (h Hand) Explain() string {
+	if h.wildAt == 0 {
+		return h.Kind.String()
+	}
+
+	var wilds strings.Builder
+	for i := 0; i < len(h.Cards); i++ {
+		if h.wildAt&(1<<i) != 0 {
+			wilds.WriteByte(h.Cards[i])
+		}
+	}
+	return fmt.Sprintf("%v via %s as %c", h.Kind, wilds.String(), h.As)
+}
+
+func (h Hand) String() string {
+	return fmt.Sprintf("%s (%s)", h.Cards, h.Explain())
+}
+
+// Rank returns each hand's rank among hands, weakest first from 1, by its
+// index. Tied hands share a rank.
+func Rank(hands []Hand) []int {
+	order := make([]int, len(hands))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return hands[order[i]].key < hands[order[j]].key })
+
+	ranks := make([]int, len(hands))
+	for pos, i := range order {
+		ranks[i] = pos + 1
+		if pos > 0 && hands[order[pos-1]].key == hands[i].key {
+			ranks[i] = ranks[order[pos-1]]
+		}
+	}
+	return ranks
+}
@@ -0,0 +1,64 @@
+package cards
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var sample = []struct {
+	cards string
+	bid   int
+}{
+	{"32T3K", 765}, {"T55J5", 684}, {"KK677", 28}, {"KTJJT", 220}, {"QQQJA", 483},
+}
+
+func TestWinnings(t *testing.T) {
+	for _, tc := range []struct {
+		rules *Rules
+		want  int
+	}{{Camel, 6440}, {Jokers, 5905}} {
+		hands := make([]Hand, len(sample))
+		for i, s := range sample {
+			hands[i] = tc.rules.MustEvaluate(s.cards)
+		}
+		winnings := 0
+		for i, rank := range Rank(hands) {
+			winnings += rank * sample[i].bid
+		}
+		if winnings != tc.want {
+			t.Errorf("rules %q wild %q: winnings %d, want %d", tc.rules.Order, tc.rules.Wild, winnings, tc.want)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	if got, want := Jokers.MustEvaluate("KTJJT").Explain(), "four of a kind via JJ as T"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func randomHands(n int, r *Rules) []string {
+	rng := rand.New(rand.NewSource(7))
+	hands := make([]string, n)
+	b := make([]byte, 5)
+	for i := range hands {
+		for j := range b {
+			b[j] = r.Order[rng.Intn(len(r.Order))]
+		}
+		hands[i] = string(b)
+	}
+	return hands
+}
+
+// BenchmarkRankMillion evaluates and ranks a million hands with jokers wild.
+func BenchmarkRankMillion(b *testing.B) {
+	cards := randomHands(1000000, Jokers)
+	hands := make([]Hand, len(cards))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j, c := range cards {
+			hands[j] = Jokers.MustEvaluate(c)
+		}
+		Rank(hands)
+	}
+}
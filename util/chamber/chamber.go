@@ -0,0 +1,187 @@
+package chamber
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Chamber is a shaft Width cells wide that rocks fall down, pushed sideways
+// by jets of gas. Each row of settled rock is a bitmask, bit i set for
+// column i from the left.
+type Chamber struct {
+	Width  int
+	Shapes []Shape
+	Jets   []int
+
+	rows  []uint64 // from base upward, the topmost holding rock
+	base  int      // height of rows[0]; everything below is out of reach
+	rocks int      // rocks fallen so far
+	jet   int      // next jet to blow
+
+	Cycle *Cycle // set once Run has skipped ahead
+}
+
+// Cycle is a repeat in the chamber's state: from rock Start on, every Length
+// more rocks raise the tower by Growth.
+type Cycle struct {
+	Start, Length, Growth int
+}
+
+func New(width int, shapes []Shape, jets []int) (*Chamber, error) {
+	if width < 1 || width > 64 {
+		return nil, fmt.Errorf("chamber: width %d is not between 1 and 64", width)
+	}
+	if len(shapes) == 0 || len(jets) == 0 {
+		return nil, fmt.Errorf("chamber: needs at least one shape and one jet")
+	}
+	for i, s := range shapes {
+		if s.Width > width {
+			return nil, fmt.Errorf("chamber: shape %d is %d wide, too wide for the chamber", i, s.Width)
+		}
+	}
+	return &Chamber{Width: width, Shapes: shapes, Jets: jets}, nil
+}
+
+func (c *Chamber) Height() int { return c.base + len(c.rows) }
+func (c *Chamber) Rocks() int  { return c.rocks }
+
+func (c *Chamber) row(y int) uint64 {
+	if y < c.base {
+		return ^uint64(0)
+	} else if y-c.base >= len(c.rows) {
+		return 0
+	}
+	return c.rows[y-c.base]
+}
+
+// fits checks whether s, with its bottom left at x, y, overlaps no wall,
+// floor or rock.
+func (c *Chamber) fits(s Shape, x, y int) bool {
+	if x < 0 || x+s.Width > c.Width || y < 0 {
+		return false
+	}
+	for i, row := range s.Rows {
+		if c.row(y+i)&(row<<x) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Drop lets the next rock fall from two cells in from the left wall and three
+// above the tower, blown by a jet before every step down, until it comes to
+// rest.
+func (c *Chamber) Drop() {
+	s := c.Shapes[c.rocks%len(c.Shapes)]
+	x, y := 2, c.Height()+3
+	for {
+		push := c.Jets[c.jet]
+		c.jet = (c.jet + 1) % len(c.Jets)
+		if c.fits(s, x+push, y) {
+			x += push
+		}
+		if !c.fits(s, x, y-1) {
+			break
+		}
+		y--
+	}
+
+	for i, row := range s.Rows {
+		for y+i-c.base >= len(c.rows) {
+			c.rows = append(c.rows, 0)
+		}
+		c.rows[y+i-c.base] |= row << x
+	}
+	c.rocks++
+	c.trim()
+}
+
+// trim forgets rows no falling rock can reach any more: air flows in from
+// above and spreads down and sideways, and the rows below the first it cannot
+// enter are sealed off.
+func (c *Chamber) trim() {
+	full := ^uint64(0) >> (64 - c.Width)
+	reach := full
+	for i := len(c.rows) - 1; i >= 0; i-- {
+		free := ^c.rows[i] & full
+		reach &= free
+		for {
+			spread := (reach | reach<<1 | reach>>1) & free
+			if spread == reach {
+				break
+			}
+			reach = spread
+		}
+		if reach == 0 {
+			c.rows = c.rows[i+1:]
+			c.base += i + 1
+			return
+		}
+	}
+}
+
+// key describes everything that decides how the tower grows from here on.
+func (c *Chamber) key() string {
+	buf := make([]byte, 0, 2*binary.MaxVarintLen64+len(c.rows))
+	buf = binary.AppendUvarint(buf, uint64(c.rocks%len(c.Shapes)))
+	buf = binary.AppendUvarint(buf, uint64(c.jet))
+	for _, row := range c.rows {
+		buf = binary.AppendUvarint(buf, row)
+	}
+	return string(buf)
+}
+
+// Run drops rocks until rocks have fallen in all. Once the reachable top of
+// the tower, the next shape and the next jet repeat, whole cycles are skipped
+// and the tower raised by as much as they would have added.
+func (c *Chamber) Run(rocks int) {
+	type mark struct{ rocks, height int }
+	seen := make(map[string]mark)
+	for c.rocks < rocks {
+		if c.Cycle == nil {
+			k := c.key()
+			if m, ok := seen[k]; ok {
+				c.Cycle = &Cycle{m.rocks, c.rocks - m.rocks, c.Height() - m.height}
+				skip := (rocks - c.rocks) / c.Cycle.Length
+				c.rocks += skip * c.Cycle.Length
+				c.base += skip * c.Cycle.Growth
+				continue
+			}
+			seen[k] = mark{c.rocks, c.Height()}
+		}
+		c.Drop()
+	}
+}
+
+// Frame draws the top rows of the tower, or all of what is left of it if
+// rows is 0, the way the puzzle draws it.
+func (c *Chamber) Frame(rows int) string {
+	bottom := 0
+	if rows > 0 && len(c.rows) > rows {
+		bottom = len(c.rows) - rows
+	}
+
+	var b strings.Builder
+	for i := len(c.rows) - 1; i >= bottom; i-- {
+		b.WriteByte('|')
+		for x := 0; x < c.Width; x++ {
+			if c.rows[i]>>x&1 == 1 {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteString("|\n")
+	}
+	if c.base+bottom == 0 {
+		b.WriteString("+" + strings.Repeat("-", c.Width) + "+\n")
+	} else {
+		b.WriteString("|" + strings.Repeat("~", c.Width) + "|\n")
+	}
+	return b.String()
+}
+
+func (c *Chamber) Print(rows int) {
+	fmt.Print(c.Frame(rows))
+}
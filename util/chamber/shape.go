@@ -0,0 +1,125 @@
+package chamber
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shape is a rock as bitmask rows, bottom first, with bit i set for a rock
+// cell i columns in from the rock's left edge.
+type Shape struct {
+	Rows  []uint64
+	Width int
+}
+
+// ParseShape reads a rock drawn with '#' for rock and '.' for air, top row
+// first.
+func ParseShape(lines []string) (Shape, error) {
+	var s Shape
+	for i := len(lines) - 1; i >= 0; i-- {
+		var row uint64
+		for x, ch := range lines[i] {
+			switch ch {
+			case '#':
+				row |= 1 << x
+				if x+1 > s.Width {
+					s.Width = x + 1
+				}
+			case '.':
+			default:
+				return Shape{}, fmt.Errorf("chamber: shape row %q: unknown cell %q", lines[i], ch)
+			}
+		}
+		s.Rows = append(s.Rows, row)
+	}
+	if s.Width == 0 {
+		return Shape{}, fmt.Errorf("chamber: shape has no rock")
+	}
+	if s.Width > 64 {
+		return Shape{}, fmt.Errorf("chamber: shape is %d wide, more than 64", s.Width)
+	}
+	return s, nil
+}
+
+// ParseShapes reads drawings separated by blank lines.
+func ParseShapes(input []string) ([]Shape, error) {
+	shapes := make([]Shape, 0)
+	for start := 0; start < len(input); {
+		if input[start] == "" {
+			start++
+			continue
+		}
+		end := start
+		for end < len(input) && input[end] != "" {
+			end++
+		}
+		s, err := ParseShape(input[start:end])
+		if err != nil {
+			return nil, err
+		}
+		shapes = append(shapes, s)
+		start = end
+	}
+	return shapes, nil
+}
+
+func (s Shape) String() string {
+	var b strings.Builder
+	for i := len(s.Rows) - 1; i >= 0; i-- {
+		for x := 0; x < s.Width; x++ {
+			if s.Rows[i]>>x&1 == 1 {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Rocks are the five shapes that fall in the elephants' chamber, in order.
+var Rocks = mustShapes(`####
+
+.#.
+###
+.#.
+
+..#
+..#
+###
+
+#
+#
+#
+#
+
+##
+##`)
+
+func mustShapes(art string) []Shape {
+	shapes, err := ParseShapes(strings.Split(art, "\n"))
+	if err != nil {
+		panic(err)
+	}
+	return shapes
+}
+
+// ParseJets reads a line of '<' and '>' as pushes of -1 and 1.
+func ParseJets(line string) ([]int, error) {
+	jets := make([]int, len(line))
+	for i, ch := range line {
+		switch ch {
+		case '<':
+			jets[i] = -1
+		case '>':
+			jets[i] = 1
+		default:
+			return nil, fmt.Errorf("chamber: jet %d is %q, not < or >", i+1, ch)
+		}
+	}
+	if len(jets) == 0 {
+		return nil, fmt.Errorf("chamber: no jets")
+	}
+	return jets, nil
+}
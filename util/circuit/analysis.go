@@ -0,0 +1,228 @@
+package circuit
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Counter is a sub-circuit driven only by one broadcaster output, whose
+// state cycles independently of the rest of the circuit.
+type Counter struct {
+	Entry   int   // broadcaster target feeding the counter
+	Modules []int // sorted
+	Exits   []int // modules outside the counter that it sends to
+
+	Offset int   // press after which the state starts repeating
+	Period int   // presses per repetition
+	Fires  []int // presses, up to Offset+Period, sending high out of the counter
+}
+
+// Counters finds the independent sub-circuits hanging off the broadcaster,
+// and simulates up to maxPresses presses to measure their periods. The
+// circuit is reset before and after.
+func (c *Circuit) Counters(maxPresses int) ([]Counter, error) {
+	bc := c.index[Broadcaster]
+	targets := c.Modules[bc].Outputs
+
+	reached := make([]int, len(c.Modules))
+	reach := make([]map[int]bool, len(targets))
+	for i, t := range targets {
+		reach[i] = c.downstream(t, bc)
+		for m := range reach[i] {
+			reached[m]++
+		}
+	}
+
+	counters := make([]Counter, len(targets))
+	owner := make(map[int]int)
+	for i, t := range targets {
+		counter := Counter{Entry: t}
+		for m := range reach[i] {
+			if reached[m] == 1 {
+				counter.Modules = append(counter.Modules, m)
+				owner[m] = i
+			}
+		}
+		if len(counter.Modules) == 0 {
+			return nil, fmt.Errorf("%s feeds no module of its own", c.Modules[t].Name)
+		}
+		sort.Ints(counter.Modules)
+		counters[i] = counter
+	}
+
+	for i := range counters {
+		counter := &counters[i]
+		for _, m := range counter.Modules {
+			for _, in := range c.Modules[m].Inputs {
+				if o, found := owner[in]; in != bc && (!found || o != i) {
+					return nil, fmt.Errorf("counter at %s is not independent: %s feeds %s",
+						c.Modules[counter.Entry].Name, c.Modules[in].Name, c.Modules[m].Name)
+				}
+			}
+			for _, out := range c.Modules[m].Outputs {
+				if o, found := owner[out]; (!found || o != i) && !contains(counter.Exits, out) {
+					counter.Exits = append(counter.Exits, out)
+				}
+			}
+		}
+	}
+
+	return counters, c.measure(counters, maxPresses)
+}
+
+// downstream lists every module reachable from start without passing
+// through the excluded module.
+func (c *Circuit) downstream(start, exclude int) map[int]bool {
+	seen := map[int]bool{start: true}
+	queue := []int{start}
+	for len(queue) > 0 {
+		m := queue[0]
+		queue = queue[1:]
+		for _, out := range c.Modules[m].Outputs {
+			if out != exclude && !seen[out] {
+				seen[out] = true
+				queue = append(queue, out)
+			}
+		}
+	}
+	return seen
+}
+
+func (c *Circuit) measure(counters []Counter, maxPresses int) error {
+	watch := c.Watch
+	defer func() {
+		c.Watch = watch
+		c.Reset()
+	}()
+	c.Reset()
+
+	member := make(map[int]int)
+	for i, counter := range counters {
+		for _, m := range counter.Modules {
+			member[m] = i
+		}
+	}
+
+	c.Watch = func(p Pulse) {
+		if !p.Signal || p.From < 0 {
+			return
+		}
+		i, found := member[p.From]
+		if !found {
+			return
+		}
+		if j, found := member[p.To]; found && j == i {
+			return
+		}
+
+		counter := &counters[i]
+		fires := counter.Fires
+		if counter.Period == 0 && (len(fires) == 0 || fires[len(fires)-1] != c.Presses) {
+			counter.Fires = append(fires, c.Presses)
+		}
+	}
+
+	seen := make([]map[string]int, len(counters))
+	for i, counter := range counters {
+		seen[i] = map[string]int{c.state(counter.Modules): 0}
+	}
+
+	remaining := len(counters)
+	for remaining > 0 && c.Presses < maxPresses {
+		c.Press()
+		for i := range counters {
+			counter := &counters[i]
+			if counter.Period != 0 {
+				continue
+			}
+
+			s := c.state(counter.Modules)
+			if prev, found := seen[i][s]; found {
+				counter.Offset, counter.Period = prev, c.Presses-prev
+				remaining--
+			} else {
+				seen[i][s] = c.Presses
+			}
+		}
+	}
+
+	if remaining > 0 {
+		return fmt.Errorf("%d counters did not repeat within %d presses", remaining, maxPresses)
+	}
+	return nil
+}
+
+// Coincide returns the first press on which every counter sends high out.
+// Each counter must fire exactly once per period once it is repeating.
+func Coincide(counters []Counter) (int, error) {
+	press, modulus := big.NewInt(0), big.NewInt(1)
+	latest := 0
+	for _, counter := range counters {
+		fires := make([]int, 0, 1)
+		for _, f := range counter.Fires {
+			if f > counter.Offset {
+				fires = append(fires, f)
+			}
+		}
+		if len(fires) != 1 {
+			return 0, fmt.Errorf("counter at %d fires %d times per period of %d", counter.Entry, len(fires), counter.Period)
+		}
+		if fires[0] > latest {
+			latest = fires[0]
+		}
+
+		var err error
+		press, modulus, err = crt(press, modulus, big.NewInt(int64(fires[0])), big.NewInt(int64(counter.Period)))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// Smallest press >= latest in the residue class
+	if gap := latest - int(press.Int64()); gap > 0 {
+		steps := (int64(gap) + modulus.Int64() - 1) / modulus.Int64()
+		press.Add(press, new(big.Int).Mul(modulus, big.NewInt(steps)))
+	}
+	if !press.IsInt64() {
+		return 0, fmt.Errorf("coinciding press %s overflows int", press)
+	}
+	return int(press.Int64()), nil
+}
+
+// crt merges x = a1 (mod m1) and x = a2 (mod m2), with moduli not
+// necessarily coprime.
+func crt(a1, m1, a2, m2 *big.Int) (*big.Int, *big.Int, error) {
+	g, p := new(big.Int), new(big.Int)
+	g.GCD(p, nil, m1, m2)
+
+	diff := new(big.Int).Sub(a2, a1)
+	if new(big.Int).Mod(diff, g).Sign() != 0 {
+		return nil, nil, fmt.Errorf("counters never fire together")
+	}
+
+	// x = a1 + m1 * (diff/g * p mod m2/g), where p is m1/g's inverse mod m2/g
+	m2g := new(big.Int).Quo(m2, g)
+	k := new(big.Int).Quo(diff, g)
+	k.Mul(k, p).Mod(k, m2g)
+
+	lcm := new(big.Int).Mul(m1, m2g)
+	x := new(big.Int).Mul(m1, k)
+	x.Add(x, a1).Mod(x, lcm)
+	return x, lcm, nil
+}
+
+func (c *Circuit) CounterString(counter Counter) string {
+	names := make([]string, len(counter.Modules))
+	for i, m := range counter.Modules {
+		names[i] = c.Modules[m].Name
+	}
+	exits := make([]string, len(counter.Exits))
+	for i, m := range counter.Exits {
+		exits[i] = c.Modules[m].Name
+	}
+	return fmt.Sprintf("counter %s -> %s: %d modules, offset %d, period %d, fires at %v (%s)",
+		c.Modules[counter.Entry].Name, strings.Join(exits, ","), len(names),
+		counter.Offset, counter.Period, counter.Fires, strings.Join(names, " "))
+}
@@ -0,0 +1,261 @@
+package circuit
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	Low, High = false, true
+
+	Broadcaster = "broadcaster"
+	Button      = "button"
+)
+
+type Kind byte
+
+const (
+	Sink        Kind = 0
+	FlipFlop    Kind = '%'
+	Conjunction Kind = '&'
+	Broadcast   Kind = 'b'
+)
+
+type Module struct {
+	Name    string
+	Kind    Kind
+	Inputs  []int
+	Outputs []int
+}
+
+// Pulse travels from module From to module To; From is -1 for the button.
+type Pulse struct {
+	From, To int
+	Signal   bool
+}
+
+// Circuit is a parsed module spec plus its mutable state. Modules are
+// addressed by index; untyped modules such as rx are sinks.
+type Circuit struct {
+	Modules []*Module
+	index   map[string]int
+
+	on     []bool         // flip-flop state
+	memory []map[int]bool // conjunction input memory
+	highs  []int          // high inputs remembered per conjunction
+
+	Presses   int
+	LowCount  []int // pulses received per module
+	HighCount []int
+
+	// Watch, if set, sees every pulse as it is delivered
+	Watch func(p Pulse)
+}
+
+func Parse(input []string) (*Circuit, error) {
+	c := &Circuit{index: make(map[string]int)}
+
+	targets := make([][]string, 0, len(input))
+	for _, line := range input {
+		name, after, found := strings.Cut(line, " -> ")
+		if !found || name == "" {
+			return nil, fmt.Errorf("module %q: expected name -> targets", line)
+		}
+
+		kind := Broadcast
+		switch name[0] {
+		case '%', '&':
+			kind = Kind(name[0])
+			name = name[1:]
+		default:
+			if name != Broadcaster {
+				return nil, fmt.Errorf("module %q: unknown type", line)
+			}
+		}
+		if _, found := c.index[name]; found {
+			return nil, fmt.Errorf("module %s defined twice", name)
+		}
+
+		c.add(name, kind)
+		targets = append(targets, strings.Split(after, ", "))
+	}
+	if _, found := c.index[Broadcaster]; !found {
+		return nil, fmt.Errorf("no %s module", Broadcaster)
+	}
+
+	for i, names := range targets {
+		for _, name := range names {
+			j, found := c.index[name]
+			if !found {
+				j = c.add(name, Sink)
+			}
+			c.Modules[i].Outputs = append(c.Modules[i].Outputs, j)
+			if !contains(c.Modules[j].Inputs, i) {
+				c.Modules[j].Inputs = append(c.Modules[j].Inputs, i)
+			}
+		}
+	}
+
+	c.Reset()
+	return c, nil
+}
+
+func contains(list []int, n int) bool {
+	for _, m := range list {
+		if m == n {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Circuit) add(name string, kind Kind) int {
+	c.index[name] = len(c.Modules)
+	c.Modules = append(c.Modules, &Module{Name: name, Kind: kind})
+	return len(c.Modules) - 1
+}
+
+// Index returns the index of the named module, or -1.
+func (c *Circuit) Index(name string) int {
+	if i, found := c.index[name]; found {
+		return i
+	}
+	return -1
+}
+
+// Reset turns every flip-flop off, clears conjunction memory and counters.
+func (c *Circuit) Reset() {
+	n := len(c.Modules)
+	c.on = make([]bool, n)
+	c.memory = make([]map[int]bool, n)
+	c.highs = make([]int, n)
+	for i, m := range c.Modules {
+		if m.Kind == Conjunction {
+			c.memory[i] = make(map[int]bool)
+		}
+	}
+	c.Presses = 0
+	c.LowCount = make([]int, n)
+	c.HighCount = make([]int, n)
+}
+
+func (c *Circuit) PulseString(p Pulse) string {
+	from := Button
+	if p.From >= 0 {
+		from = c.Modules[p.From].Name
+	}
+	signal := "low"
+	if p.Signal {
+		signal = "high"
+	}
+	return fmt.Sprintf("%s -%s-> %s", from, signal, c.Modules[p.To].Name)
+}
+
+// Press pushes the button once and delivers every resulting pulse.
+func (c *Circuit) Press() {
+	c.Presses++
+	queue := []Pulse{{-1, c.index[Broadcaster], Low}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		queue = c.deliver(p, queue)
+	}
+}
+
+func (c *Circuit) Run(presses int) {
+	for i := 0; i < presses; i++ {
+		c.Press()
+	}
+}
+
+func (c *Circuit) deliver(p Pulse, queue []Pulse) []Pulse {
+	if p.Signal {
+		c.HighCount[p.To]++
+	} else {
+		c.LowCount[p.To]++
+	}
+	if c.Watch != nil {
+		c.Watch(p)
+	}
+
+	m := c.Modules[p.To]
+	var out bool
+	switch m.Kind {
+	case Broadcast:
+		out = p.Signal
+	case FlipFlop:
+		if p.Signal {
+			return queue
+		}
+		c.on[p.To] = !c.on[p.To]
+		out = c.on[p.To]
+	case Conjunction:
+		if prev := c.memory[p.To][p.From]; prev != p.Signal {
+			c.memory[p.To][p.From] = p.Signal
+			if p.Signal {
+				c.highs[p.To]++
+			} else {
+				c.highs[p.To]--
+			}
+		}
+		out = c.highs[p.To] != len(m.Inputs)
+	default:
+		return queue
+	}
+
+	for _, t := range m.Outputs {
+		queue = append(queue, Pulse{p.To, t, out})
+	}
+	return queue
+}
+
+// Totals sums the pulses received by every module, including the button's.
+func (c *Circuit) Totals() (lows, highs int) {
+	for i := range c.Modules {
+		lows += c.LowCount[i]
+		highs += c.HighCount[i]
+	}
+	return
+}
+
+// Report lists per-module pulse counts in spec order.
+func (c *Circuit) Report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "after %d presses:\n", c.Presses)
+	for i, m := range c.Modules {
+		fmt.Fprintf(&b, "%c%-12s low %-8d high %d\n", kindSymbol(m.Kind), m.Name, c.LowCount[i], c.HighCount[i])
+	}
+	return b.String()
+}
+
+func kindSymbol(k Kind) byte {
+	switch k {
+	case FlipFlop, Conjunction:
+		return byte(k)
+	default:
+		return ' '
+	}
+}
+
+// state renders the flip-flop and conjunction memory of the given modules.
+func (c *Circuit) state(modules []int) string {
+	b := make([]byte, 0, len(modules))
+	for _, i := range modules {
+		switch c.Modules[i].Kind {
+		case FlipFlop:
+			b = append(b, boolByte(c.on[i]))
+		case Conjunction:
+			for _, in := range c.Modules[i].Inputs {
+				b = append(b, boolByte(c.memory[i][in]))
+			}
+		}
+	}
+	return string(b)
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return '1'
+	}
+	return '0'
+}
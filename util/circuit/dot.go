@@ -0,0 +1,52 @@
+package circuit
+
+import (
+	"fmt"
+	"strings"
+)
+
+var dotShapes = map[Kind]string{
+	Broadcast:   "Mdiamond",
+	FlipFlop:    "box",
+	Conjunction: "invtriangle",
+	Sink:        "doublecircle",
+}
+
+// DOT renders the wiring as a Graphviz digraph, drawing each counter as a
+// cluster. Pass nil counters for a flat graph.
+func (c *Circuit) DOT(counters []Counter) string {
+	var b strings.Builder
+	b.WriteString("digraph circuit {\n")
+	b.WriteString("\trankdir=LR;\n")
+
+	clustered := make(map[int]bool)
+	for i, counter := range counters {
+		fmt.Fprintf(&b, "\tsubgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "\t\tlabel=\"period %d\";\n", counter.Period)
+		for _, m := range counter.Modules {
+			b.WriteString("\t\t" + c.dotNode(m) + "\n")
+			clustered[m] = true
+		}
+		b.WriteString("\t}\n")
+	}
+
+	for i := range c.Modules {
+		if !clustered[i] {
+			b.WriteString("\t" + c.dotNode(i) + "\n")
+		}
+	}
+
+	for _, m := range c.Modules {
+		for _, out := range m.Outputs {
+			fmt.Fprintf(&b, "\t%q -> %q;\n", m.Name, c.Modules[out].Name)
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (c *Circuit) dotNode(i int) string {
+	m := c.Modules[i]
+	return fmt.Sprintf("%q [shape=%s];", m.Name, dotShapes[m.Kind])
+}
@@ -0,0 +1,192 @@
+package crane
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Move is a rearrangement step; stacks are numbered from 1 as written.
+type Move struct {
+	Count, From, To int
+}
+
+func ParseMove(line string) (Move, error) {
+	var m Move
+	if _, err := fmt.Sscanf(line, "move %d from %d to %d", &m.Count, &m.From, &m.To); err != nil {
+		return m, fmt.Errorf("crane: bad move %q: %w", line, err)
+	}
+	return m, nil
+}
+
+func (m Move) String() string {
+	return fmt.Sprintf("move %d from %d to %d", m.Count, m.From, m.To)
+}
+
+// Parse reads a drawing, a blank line, then one move per line.
+func Parse(input []string) (Stacks, []Move, error) {
+	blank := len(input)
+	for i, line := range input {
+		if line == "" {
+			blank = i
+			break
+		}
+	}
+
+	stacks, err := ParseDrawing(input[:blank])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	moves := make([]Move, 0)
+	if blank < len(input) {
+		for _, line := range input[blank+1:] {
+			if line == "" {
+				continue
+			}
+			m, err := ParseMove(line)
+			if err != nil {
+				return nil, nil, err
+			}
+			moves = append(moves, m)
+		}
+	}
+	return stacks, moves, nil
+}
+
+// Model is a crane's way of carrying crates: given the crates lifted off a
+// stack, bottom first, it returns them in the order they are set down.
+type Model func(lifted []byte) []byte
+
+// CrateMover9000 carries one crate at a time, reversing their order.
+func CrateMover9000(lifted []byte) []byte {
+	placed := make([]byte, len(lifted))
+	for i, c := range lifted {
+		placed[len(lifted)-1-i] = c
+	}
+	return placed
+}
+
+// CrateMover9001 carries them all at once, keeping their order.
+func CrateMover9001(lifted []byte) []byte {
+	return append([]byte{}, lifted...)
+}
+
+var ErrNothingToUndo = errors.New("crane: nothing to undo")
+var ErrNothingToRedo = errors.New("crane: nothing to redo")
+
+// step records a move with the crates it lifted, so it can be undone
+// whatever the model.
+type step struct {
+	move   Move
+	lifted []byte
+}
+
+// Crane rearranges Stacks move by move, remembering its moves for Undo and
+// Redo.
+type Crane struct {
+	Model  Model
+	Stacks Stacks
+
+	done   []step
+	undone []Move
+}
+
+// New works on a copy of stacks.
+func New(stacks Stacks, model Model) *Crane {
+	return &Crane{Model: model, Stacks: stacks.Clone()}
+}
+
+// Check reports why m cannot be applied, if it cannot.
+func (c *Crane) Check(m Move) error {
+	for _, n := range []int{m.From, m.To} {
+		if n < 1 || n > len(c.Stacks) {
+			return fmt.Errorf("crane: %v: no stack %d among %d", m, n, len(c.Stacks))
+		}
+	}
+	switch {
+	case m.From == m.To:
+		return fmt.Errorf("crane: %v: source and destination are the same stack", m)
+	case m.Count < 1:
+		return fmt.Errorf("crane: %v: must move at least one crate", m)
+	case m.Count > len(c.Stacks[m.From-1]):
+		return fmt.Errorf("crane: %v: stack %d holds %d", m, m.From, len(c.Stacks[m.From-1]))
+	}
+	return nil
+}
+
+// Apply performs m and forgets any undone moves.
+func (c *Crane) Apply(m Move) error {
+	if err := c.apply(m); err != nil {
+		return err
+	}
+	c.undone = c.undone[:0]
+	return nil
+}
+
+func (c *Crane) apply(m Move) error {
+	if err := c.Check(m); err != nil {
+		return err
+	}
+
+	from, to := m.From-1, m.To-1
+	n := len(c.Stacks[from]) - m.Count
+	lifted := append([]byte{}, c.Stacks[from][n:]...)
+	c.Stacks[from] = c.Stacks[from][:n]
+	c.Stacks[to] = append(c.Stacks[to], c.Model(lifted)...)
+
+	c.done = append(c.done, step{m, lifted})
+	return nil
+}
+
+// Run applies moves in order, calling watch, if set, after each one. It
+// stops at the first invalid move.
+func (c *Crane) Run(moves []Move, watch func(i int, m Move)) error {
+	for i, m := range moves {
+		if err := c.Apply(m); err != nil {
+			return fmt.Errorf("move %d: %w", i+1, err)
+		}
+		if watch != nil {
+			watch(i, m)
+		}
+	}
+	return nil
+}
+
+// Undo takes back the last move, putting the lifted crates back as they
+// were.
+func (c *Crane) Undo() error {
+	if len(c.done) == 0 {
+		return ErrNothingToUndo
+	}
+	last := c.done[len(c.done)-1]
+	c.done = c.done[:len(c.done)-1]
+
+	from, to := last.move.From-1, last.move.To-1
+	c.Stacks[to] = c.Stacks[to][:len(c.Stacks[to])-last.move.Count]
+	c.Stacks[from] = append(c.Stacks[from], last.lifted...)
+
+	c.undone = append(c.undone, last.move)
+	return nil
+}
+
+// Redo replays the most recently undone move.
+func (c *Crane) Redo() error {
+	if len(c.undone) == 0 {
+		return ErrNothingToRedo
+	}
+	m := c.undone[len(c.undone)-1]
+	if err := c.apply(m); err != nil {
+		return err
+	}
+	c.undone = c.undone[:len(c.undone)-1]
+	return nil
+}
+
+// History lists the moves applied and not undone.
+func (c *Crane) History() []Move {
+	moves := make([]Move, len(c.done))
+	for i, s := range c.done {
+		moves[i] = s.move
+	}
+	return moves
+}
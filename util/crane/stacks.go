@@ -0,0 +1,112 @@
+package crane
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stacks holds crates bottom first, one slice per stack.
+type Stacks [][]byte
+
+func (s Stacks) Clone() Stacks {
+	clone := make(Stacks, len(s))
+	for i, stack := range s {
+		clone[i] = append([]byte{}, stack...)
+	}
+	return clone
+}
+
+// Top lists the top crate of each stack, skipping empty ones.
+func (s Stacks) Top() string {
+	var b strings.Builder
+	for _, stack := range s {
+		if len(stack) > 0 {
+			b.WriteByte(stack[len(stack)-1])
+		}
+	}
+	return b.String()
+}
+
+// ParseDrawing reads a drawing such as
+//
+//	    [D]
+//	[N] [C]
+//	[Z] [M] [P]
+//	 1   2   3
+//
+// where the last line numbers the stacks.
+func ParseDrawing(lines []string) (Stacks, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("crane: empty drawing")
+	}
+
+	labels := strings.Fields(lines[len(lines)-1])
+	for i, label := range labels {
+		if label != fmt.Sprint(i+1) {
+			return nil, fmt.Errorf("crane: stack %d labelled %q", i+1, label)
+		}
+	}
+
+	stacks := make(Stacks, len(labels))
+	for i := range stacks {
+		stacks[i] = make([]byte, 0)
+	}
+	for row := len(lines) - 2; row >= 0; row-- {
+		line := lines[row]
+		for i := range stacks {
+			if i*4 >= len(line) {
+				break
+			}
+			cell := line[i*4:]
+			if len(cell) > 3 {
+				cell = cell[:3]
+			}
+
+			switch {
+			case strings.TrimSpace(cell) == "":
+				continue
+			case len(cell) == 3 && cell[0] == '[' && cell[2] == ']':
+				if len(stacks[i]) != len(lines)-2-row {
+					return nil, fmt.Errorf("crane: crate %s floats above stack %d", cell, i+1)
+				}
+				stacks[i] = append(stacks[i], cell[1])
+			default:
+				return nil, fmt.Errorf("crane: line %d: bad crate %q", row+1, cell)
+			}
+		}
+	}
+	return stacks, nil
+}
+
+// Render draws the stacks the way ParseDrawing reads them.
+func (s Stacks) Render() []string {
+	height := 0
+	for _, stack := range s {
+		if len(stack) > height {
+			height = len(stack)
+		}
+	}
+
+	lines := make([]string, 0, height+1)
+	for level := height - 1; level >= 0; level-- {
+		cells := make([]string, len(s))
+		for i, stack := range s {
+			if level < len(stack) {
+				cells[i] = fmt.Sprintf("[%c]", stack[level])
+			} else {
+				cells[i] = "   "
+			}
+		}
+		lines = append(lines, strings.Join(cells, " "))
+	}
+
+	labels := make([]string, len(s))
+	for i := range s {
+		labels[i] = fmt.Sprintf(" %d ", i+1)
+	}
+	return append(lines, strings.Join(labels, " "))
+}
+
+func (s Stacks) String() string {
+	return strings.Join(s.Render(), "\n") + "\n"
+}
@@ -0,0 +1,294 @@
+package cubenet
+
+import (
+	"fmt"
+	"math"
+)
+
+// Dir is a heading on the flat map, numbered clockwise from right as the
+// password scores it.
+type Dir int
+
+const (
+	Right Dir = iota
+	Down
+	Left
+	Up
+)
+
+var (
+	dirNames = [4]string{"right", "down", "left", "up"}
+	dirSteps = [4]Pos{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+)
+
+func (d Dir) String() string { return dirNames[d] }
+func (d Dir) Opposite() Dir  { return (d + 2) % 4 }
+
+// Turn turns clockwise for 'R' and anticlockwise for 'L'.
+func (d Dir) Turn(turn byte) Dir {
+	switch turn {
+	case 'R':
+		return (d + 1) % 4
+	case 'L':
+		return (d + 3) % 4
+	default:
+		panic(fmt.Sprintf("cubenet: invalid turn %q", turn))
+	}
+}
+
+type Pos struct {
+	Row, Col int
+}
+
+func (p Pos) Add(o Pos) Pos { return Pos{p.Row + o.Row, p.Col + o.Col} }
+
+// vec is a direction in space, one unit along one axis.
+type vec [3]int
+
+func (v vec) neg() vec        { return vec{-v[0], -v[1], -v[2]} }
+func (v vec) dot(o vec) int   { return v[0]*o[0] + v[1]*o[1] + v[2]*o[2] }
+func (v vec) scale(k int) vec { return vec{v[0] * k, v[1] * k, v[2] * k} }
+func (v vec) plus(o vec) vec  { return vec{v[0] + o[0], v[1] + o[1], v[2] + o[2]} }
+func (v vec) String() string  { return fmt.Sprint([3]int(v)) }
+
+// Face is a square of the net, Size cells across, folded into place on the
+// cube: Normal points out of the cube, and Across and Downward are where the
+// face's columns and rows run once folded.
+type Face struct {
+	Origin                   Pos // top left cell on the map
+	Normal, Across, Downward vec
+}
+
+// edge is the direction in space that leaving a face by side d heads.
+func (f *Face) edge(d Dir) vec {
+	switch d {
+	case Right:
+		return f.Across
+	case Down:
+		return f.Downward
+	case Left:
+		return f.Across.neg()
+	default:
+		return f.Downward.neg()
+	}
+}
+
+// Net is a map of open ('.') and wall ('#') cells, blank (' ') outside,
+// that may fold into a cube.
+type Net struct {
+	Size  int
+	Faces []*Face
+	lines []string
+	tiles map[Pos]int // face index by tile, a tile being Size by Size cells
+}
+
+// ParseFlat reads a map of any shape without folding it, for walking with
+// StepFlat. It has no faces, so Step and Edge do not work on it.
+func ParseFlat(lines []string) (*Net, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("cubenet: empty map")
+	}
+	for row, line := range lines {
+		for col := 0; col < len(line); col++ {
+			switch line[col] {
+			case ' ', '.', '#':
+			default:
+				return nil, fmt.Errorf("cubenet: %q at row %d, column %d is not a map cell", line[col], row, col)
+			}
+		}
+	}
+	return &Net{lines: lines}, nil
+}
+
+// Parse reads a map as ParseFlat does, finds the face size from the cell
+// count, then folds the net by walking from face to face across shared
+// edges. Any of the eleven cube nets works, in any orientation.
+func Parse(lines []string) (*Net, error) {
+	n, err := ParseFlat(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	cells := 0
+	for _, line := range lines {
+		for i := 0; i < len(line); i++ {
+			if line[i] != ' ' {
+				cells++
+			}
+		}
+	}
+	size := int(math.Sqrt(float64(cells / 6)))
+	if size == 0 || 6*size*size != cells {
+		return nil, fmt.Errorf("cubenet: %d cells cannot make six square faces", cells)
+	}
+
+	n.Size, n.tiles = size, make(map[Pos]int)
+	for row := 0; row < len(lines); row += size {
+		for col := 0; col < len(lines[row]); col += size {
+			if lines[row][col] == ' ' {
+				continue
+			}
+			n.tiles[Pos{row / size, col / size}] = len(n.Faces)
+			n.Faces = append(n.Faces, &Face{Origin: Pos{row, col}})
+		}
+	}
+	if len(n.Faces) != 6 {
+		return nil, fmt.Errorf("cubenet: found %d faces of size %d", len(n.Faces), size)
+	}
+	for _, f := range n.Faces {
+		for r := 0; r < size; r++ {
+			for c := 0; c < size; c++ {
+				if !n.OnMap(f.Origin.Add(Pos{r, c})) {
+					return nil, fmt.Errorf("cubenet: face at %v has a hole at %v", f.Origin, f.Origin.Add(Pos{r, c}))
+				}
+			}
+		}
+	}
+
+	if err := n.fold(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// fold lays the first face flat, facing up out of the cube, and turns each
+// neighbour on the map down over their shared edge.
+func (n *Net) fold() error {
+	first := n.Faces[0]
+	first.Normal, first.Across, first.Downward = vec{0, 0, 1}, vec{1, 0, 0}, vec{0, 1, 0}
+
+	placed := map[*Face]bool{first: true}
+	normals := map[vec]*Face{first.Normal: first}
+	queue := []*Face{first}
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+		tile := Pos{f.Origin.Row / n.Size, f.Origin.Col / n.Size}
+
+		for d := Right; d <= Up; d++ {
+			i, ok := n.tiles[tile.Add(dirSteps[d])]
+			if !ok || placed[n.Faces[i]] {
+				continue
+			}
+
+			g := n.Faces[i]
+			g.Normal, g.Across, g.Downward = f.edge(d), f.Across, f.Downward
+			switch d {
+			case Right:
+				g.Across = f.Normal.neg()
+			case Left:
+				g.Across = f.Normal
+			case Down:
+				g.Downward = f.Normal.neg()
+			case Up:
+				g.Downward = f.Normal
+			}
+
+			if other, ok := normals[g.Normal]; ok {
+				return fmt.Errorf("cubenet: faces at %v and %v fold onto the same side", other.Origin, g.Origin)
+			}
+			normals[g.Normal] = g
+			placed[g] = true
+			queue = append(queue, g)
+		}
+	}
+
+	if len(placed) != 6 {
+		return fmt.Errorf("cubenet: net falls apart into pieces")
+	}
+	return nil
+}
+
+func (n *Net) OnMap(p Pos) bool {
+	return p.Row >= 0 && p.Row < len(n.lines) && p.Col >= 0 && p.Col < len(n.lines[p.Row]) &&
+		n.lines[p.Row][p.Col] != ' '
+}
+
+// Tile is the character at p, or ' ' off the map.
+func (n *Net) Tile(p Pos) byte {
+	if !n.OnMap(p) {
+		return ' '
+	}
+	return n.lines[p.Row][p.Col]
+}
+
+func (n *Net) Open(p Pos) bool { return n.Tile(p) == '.' }
+
+// Start is the leftmost open cell of the top row.
+func (n *Net) Start() Pos {
+	for col := 0; col < len(n.lines[0]); col++ {
+		if n.Open(Pos{0, col}) {
+			return Pos{0, col}
+		}
+	}
+	panic("cubenet: no open cell on the top row")
+}
+
+func (n *Net) face(p Pos) *Face {
+	return n.Faces[n.tiles[Pos{p.Row / n.Size, p.Col / n.Size}]]
+}
+
+// Edge finds which face, and which side of it, adjoins side d of face f once
+// folded.
+func (n *Net) Edge(f *Face, d Dir) (*Face, Dir) {
+	normal := f.edge(d)
+	for _, g := range n.Faces {
+		if g.Normal != normal {
+			continue
+		}
+		for side := Right; side <= Up; side++ {
+			if g.edge(side) == f.Normal {
+				return g, side
+			}
+		}
+	}
+	panic("cubenet: cube is not closed")
+}
+
+// Step moves one cell from p heading d across the surface of the folded
+// cube, turning as needed when crossing an edge. Walls are not checked.
+func (n *Net) Step(p Pos, d Dir) (Pos, Dir) {
+	if next := p.Add(dirSteps[d]); n.OnMap(next) {
+		return next, d
+	}
+
+	// Place the cell in space, with the cube spanning -Size..Size, and move
+	// it over the edge onto the next face
+	f := n.face(p)
+	g, side := n.Edge(f, d)
+	r, c := p.Row-f.Origin.Row, p.Col-f.Origin.Col
+	space := f.Normal.scale(n.Size).
+		plus(f.Across.scale(2*c + 1 - n.Size)).
+		plus(f.Downward.scale(2*r + 1 - n.Size))
+	space = space.plus(g.Normal).plus(f.Normal.neg())
+
+	r, c = (space.dot(g.Downward)+n.Size-1)/2, (space.dot(g.Across)+n.Size-1)/2
+	return g.Origin.Add(Pos{r, c}), side.Opposite()
+}
+
+// StepFlat moves one cell from p heading d, wrapping around to the far side
+// of the map's row or column when falling off the edge.
+func (n *Net) StepFlat(p Pos, d Dir) (Pos, Dir) {
+	next := p.Add(dirSteps[d])
+	if n.OnMap(next) {
+		return next, d
+	}
+
+	back := dirSteps[d.Opposite()]
+	for next = p; n.OnMap(next.Add(back)); next = next.Add(back) {
+	}
+	return next, d
+}
+
+// Walk moves up to steps cells from p heading d, using step to cross edges,
+// and stops early in front of a wall.
+func (n *Net) Walk(p Pos, d Dir, steps int, step func(Pos, Dir) (Pos, Dir)) (Pos, Dir) {
+	for i := 0; i < steps; i++ {
+		next, nextDir := step(p, d)
+		if !n.Open(next) {
+			break
+		}
+		p, d = next, nextDir
+	}
+	return p, d
+}
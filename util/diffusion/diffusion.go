@@ -0,0 +1,319 @@
+package diffusion
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Dir is one of the four directions an elf may propose to step in. Before
+// stepping, an elf looks at the three cells on that side of it: straight
+// ahead and both diagonals.
+type Dir int
+
+const (
+	North Dir = iota
+	South
+	West
+	East
+)
+
+func (d Dir) String() string { return [...]string{"N", "S", "W", "E"}[d] }
+
+// Rule is how elves choose: the first of Order with all three cells on that
+// side clear, trying from a start that moves Rotate places down the list
+// every round.
+type Rule struct {
+	Order  []Dir
+	Rotate int
+}
+
+// Standard is the rule from the puzzle: north, south, west, east, with the
+// first choice moving to the back each round.
+var Standard = Rule{[]Dir{North, South, West, East}, 1}
+
+// Floor holds the elves as a bitset, a row of 64-bit words for each y, with
+// bit x%64 of word x/64 set for an elf at x. The bitset grows whenever elves
+// come near its edges, so it is never much bigger than the elves' bounding
+// box.
+type Floor struct {
+	Rule  Rule
+	Round int // rounds played so far
+
+	words, rows int
+	cells       []uint64
+	x0, y0      int // input coordinates of bit 0 of row 0
+	elves       int
+
+	// Scratch space for each round: proposals, then those that go ahead
+	props [4][]uint64
+	moved [4][]uint64
+	next  []uint64
+}
+
+// margin is how many empty rows a Floor keeps above and below the elves;
+// each round they can move one further, and a clash looks two away.
+const margin = 8
+
+// Parse reads a map with '#' for an elf.
+func Parse(input []string, rule Rule) (*Floor, error) {
+	if len(rule.Order) == 0 {
+		return nil, fmt.Errorf("diffusion: rule has no directions")
+	}
+	width := 0
+	for _, line := range input {
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+
+	f := &Floor{Rule: rule, words: (width+63)/64 + 2, rows: len(input) + 2*margin}
+	f.x0, f.y0 = -64, -margin
+	f.cells = make([]uint64, f.words*f.rows)
+	for y, line := range input {
+		for x, ch := range line {
+			switch ch {
+			case '#':
+				f.set(x, y)
+				f.elves++
+			case '.':
+			default:
+				return nil, fmt.Errorf("diffusion: line %d: unknown cell %q", y+1, ch)
+			}
+		}
+	}
+	return f, nil
+}
+
+func (f *Floor) set(x, y int) {
+	x, y = x-f.x0, y-f.y0
+	f.cells[y*f.words+x/64] |= 1 << (x % 64)
+}
+
+func (f *Floor) Elves() int { return f.elves }
+
+// Has reports whether an elf stands at x, y.
+func (f *Floor) Has(x, y int) bool {
+	x, y = x-f.x0, y-f.y0
+	if x < 0 || y < 0 || x >= 64*f.words || y >= f.rows {
+		return false
+	}
+	return f.cells[y*f.words+x/64]>>(x%64)&1 == 1
+}
+
+func (f *Floor) row(cells []uint64, y int) []uint64 {
+	if y < 0 || y >= f.rows {
+		return nil
+	}
+	return cells[y*f.words : (y+1)*f.words]
+}
+
+// crowded reports whether any elf is close enough to the bitset's edges to
+// step off it, or clash with one that would.
+func (f *Floor) crowded() bool {
+	for y := 0; y < f.rows; y++ {
+		row := f.row(f.cells, y)
+		if (y < 2 || y >= f.rows-2) && f.any(row) {
+			return true
+		}
+		if row[0]&3 != 0 || row[f.words-1]>>62 != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Floor) any(row []uint64) bool {
+	for _, w := range row {
+		if w != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// grow adds a word to each side of every row and margin rows above and
+// below.
+func (f *Floor) grow() {
+	words, rows := f.words+2, f.rows+2*margin
+	cells := make([]uint64, words*rows)
+	for y := 0; y < f.rows; y++ {
+		copy(cells[(y+margin)*words+1:], f.row(f.cells, y))
+	}
+	f.words, f.rows, f.cells = words, rows, cells
+	f.x0, f.y0 = f.x0-64, f.y0-margin
+}
+
+// Step plays a round: every elf with a neighbour proposes a step by the
+// rule, and those whose step no other elf proposed take it. It returns how
+// many elves moved.
+func (f *Floor) Step() int {
+	if f.crowded() {
+		f.grow()
+	}
+	n := len(f.cells)
+	if len(f.next) != n {
+		for d := range f.props {
+			f.props[d], f.moved[d] = make([]uint64, n), make([]uint64, n)
+		}
+		f.next = make([]uint64, n)
+	}
+
+	order := make([]Dir, len(f.Rule.Order))
+	for i := range order {
+		order[i] = f.Rule.Order[(i+f.Round*f.Rule.Rotate)%len(order)]
+	}
+
+	// Proposals: the first clear side for each elf with any neighbour. The
+	// two rows at each edge are empty, so every row looked at has rows above
+	// and below it; ul, u and ur are words i-1, i and i+1 of the row above,
+	// and so on.
+	w, cells := f.words, f.cells
+	for y := 1; y < f.rows-1; y++ {
+		var ul, cl, dl uint64
+		u, c, d := cells[(y-1)*w], cells[y*w], cells[(y+1)*w]
+		for i := 0; i < w; i++ {
+			j := y*w + i
+			var ur, cr, dr uint64
+			if i+1 < w {
+				ur, cr, dr = cells[j-w+1], cells[j+1], cells[j+w+1]
+			}
+
+			var props [4]uint64
+			if c != 0 {
+				var open [4]uint64
+				open[North] = ^(u | u<<1 | ul>>63 | u>>1 | ur<<63)
+				open[South] = ^(d | d<<1 | dl>>63 | d>>1 | dr<<63)
+				open[West] = ^(u<<1 | ul>>63 | c<<1 | cl>>63 | d<<1 | dl>>63)
+				open[East] = ^(u>>1 | ur<<63 | c>>1 | cr<<63 | d>>1 | dr<<63)
+
+				waiting := c &^ (open[North] & open[South] & open[West] & open[East])
+				for _, d := range order {
+					props[d] = waiting & open[d]
+					waiting &^= open[d]
+				}
+			}
+			for d := range props {
+				f.props[d][j] = props[d]
+			}
+			ul, cl, dl, u, c, d = u, c, d, ur, cr, dr
+		}
+	}
+
+	// Only elves facing each other across a cell can propose the same one;
+	// any other pair would have seen each other. So a step goes ahead unless
+	// the elf two cells on proposed the opposite way. No elf is within two
+	// rows of the edge, so the rows checked are all there.
+	moves := 0
+	pn, ps, pw, pe := f.props[North], f.props[South], f.props[West], f.props[East]
+	mn, ms, mw, me := f.moved[North], f.moved[South], f.moved[West], f.moved[East]
+	for y := 2; y < f.rows-2; y++ {
+		for i := 0; i < w; i++ {
+			j := y*w + i
+			var eastBefore, westAfter uint64
+			if i > 0 {
+				eastBefore = pe[j-1] >> 62
+			}
+			if i+1 < w {
+				westAfter = pw[j+1] << 62
+			}
+			mn[j] = pn[j] &^ ps[j-2*w]
+			ms[j] = ps[j] &^ pn[j+2*w]
+			mw[j] = pw[j] &^ (pe[j]<<2 | eastBefore)
+			me[j] = pe[j] &^ (pw[j]>>2 | westAfter)
+			moves += bits.OnesCount64(mn[j] | ms[j] | mw[j] | me[j])
+		}
+	}
+
+	for y := 1; y < f.rows-1; y++ {
+		for i := 0; i < w; i++ {
+			j := y*w + i
+			var fromLeft, fromRight uint64
+			if i > 0 {
+				fromLeft = me[j-1] >> 63
+			}
+			if i+1 < w {
+				fromRight = mw[j+1] << 63
+			}
+			f.next[j] = cells[j]&^(mn[j]|ms[j]|mw[j]|me[j]) |
+				mn[j+w] | ms[j-w] | mw[j]>>1 | fromRight | me[j]<<1 | fromLeft
+		}
+	}
+	f.cells, f.next = f.next, f.cells
+	f.Round++
+	return moves
+}
+
+// RunTo plays rounds until round rounds have been played.
+func (f *Floor) RunTo(rounds int) {
+	for f.Round < rounds {
+		f.Step()
+	}
+}
+
+// RunUntilStable plays rounds until one in which no elf moves, and returns
+// its number, counting from 1.
+func (f *Floor) RunUntilStable() int {
+	for f.Step() > 0 {
+	}
+	return f.Round
+}
+
+// Bounds is the smallest rectangle holding every elf, corners included.
+func (f *Floor) Bounds() (minX, minY, maxX, maxY int) {
+	cols := make([]uint64, f.words)
+	minY, maxY = f.rows, -1
+	for y := 0; y < f.rows; y++ {
+		row := f.row(f.cells, y)
+		if !f.any(row) {
+			continue
+		}
+		if y < minY {
+			minY = y
+		}
+		maxY = y
+		for i, w := range row {
+			cols[i] |= w
+		}
+	}
+	minX, maxX = 64*f.words, -1
+	for i, w := range cols {
+		if w == 0 {
+			continue
+		}
+		if x := 64*i + bits.TrailingZeros64(w); x < minX {
+			minX = x
+		}
+		maxX = 64*i + 63 - bits.LeadingZeros64(w)
+	}
+	return minX + f.x0, minY + f.y0, maxX + f.x0, maxY + f.y0
+}
+
+// Empty counts the ground tiles without an elf in the bounding rectangle.
+func (f *Floor) Empty() int {
+	if f.elves == 0 {
+		return 0
+	}
+	minX, minY, maxX, maxY := f.Bounds()
+	return (maxX-minX+1)*(maxY-minY+1) - f.elves
+}
+
+// String draws the bounding rectangle the way the puzzle does.
+func (f *Floor) String() string {
+	if f.elves == 0 {
+		return ""
+	}
+	minX, minY, maxX, maxY := f.Bounds()
+	var b strings.Builder
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			if f.Has(x, y) {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package diffusion
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var sample = []string{
+	"....#..",
+	"..###.#",
+	"#...#.#",
+	".#...##",
+	"#.###..",
+	"##.#.##",
+	".#..#..",
+}
+
+func TestSample(t *testing.T) {
+	f, err := Parse(sample, Standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.RunTo(10)
+	if got := f.Empty(); got != 110 {
+		t.Errorf("empty after 10 rounds: got %d, want 110", got)
+	}
+	if got := f.RunUntilStable(); got != 20 {
+		t.Errorf("stable in round %d, want 20", got)
+	}
+}
+
+// grove is a scan the size of a real input, about half elves.
+func grove() []string {
+	rng := rand.New(rand.NewSource(23))
+	lines := make([]string, 73)
+	for y := range lines {
+		b := make([]byte, 73)
+		for x := range b {
+			b[x] = ".#"[rng.Intn(2)]
+		}
+		lines[y] = string(b)
+	}
+	return lines
+}
+
+// BenchmarkRunUntilStable runs part 2 on a real-sized scan; it should take
+// well under 100ms.
+func BenchmarkRunUntilStable(b *testing.B) {
+	lines := grove()
+	rounds := 0
+	for i := 0; i < b.N; i++ {
+		f, err := Parse(lines, Standard)
+		if err != nil {
+			b.Fatal(err)
+		}
+		rounds = f.RunUntilStable()
+	}
+	b.ReportMetric(float64(rounds), "rounds")
+}
@@ -0,0 +1,110 @@
+package garden
+
+import "fmt"
+
+// maxWarmup is how many periods the counts get to settle into quadratic
+// growth before the garden is taken not to have that structure.
+const maxWarmup = 16
+
+// Fit is a quadratic in k that gives the tiled count for Offset + k*Period
+// steps, for any k from From on. The garden repeats every Period steps both
+// ways, so once the elf's reach has spread over whole tiles, each period adds
+// a ring of tiles one longer than the last, and the counts grow
+// quadratically.
+type Fit struct {
+	Period, Offset int
+	From           int
+	values         [3]int // counts at From, From+1 and From+2 periods
+}
+
+func (g *Garden) Period() int {
+	a, b := g.Width, g.Height
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return g.Width / a * g.Height
+}
+
+// Fit finds the quadratic for counts offset steps past a whole number of
+// periods. The counts are searched for by brute force, and the fit only
+// accepted once two more periods beyond the three it is taken from agree
+// with it.
+func (g *Garden) Fit(offset int) (*Fit, error) {
+	p := g.Period()
+	if offset < 0 || offset >= p {
+		return nil, fmt.Errorf("garden: offset %d is not within the period %d", offset, p)
+	}
+
+	for limit := 2; ; limit *= 2 {
+		if limit > maxWarmup {
+			limit = maxWarmup
+		}
+		counts := g.layers(offset+(limit+4)*p, true)
+		f := make([]int, limit+5)
+		for k := range f {
+			f[k] = ending(counts, offset+k*p)
+		}
+
+		for k := 0; k <= limit; k++ {
+			if third(f[k:k+4]) == 0 && third(f[k+1:k+5]) == 0 {
+				return &Fit{p, offset, k, [3]int{f[k], f[k+1], f[k+2]}}, nil
+			}
+		}
+		if limit == maxWarmup {
+			return nil, fmt.Errorf("garden: counts %d steps apart do not grow quadratically within %d periods; the fast path needs a garden whose reach spreads evenly across tiles", p, maxWarmup)
+		}
+	}
+}
+
+func third(f []int) int {
+	return f[3] - 3*f[2] + 3*f[1] - f[0]
+}
+
+// At gives the count after steps steps, which must be Offset past a whole
+// number of periods, and at least From of them.
+func (f *Fit) At(steps int) (int, error) {
+	k := steps / f.Period
+	if steps%f.Period != f.Offset || k < f.From {
+		return 0, fmt.Errorf("garden: fit for %d + k*%d, k >= %d, cannot count %d steps", f.Offset, f.Period, f.From, steps)
+	}
+
+	// Newton's forward differences from the first value
+	t := k - f.From
+	d1 := f.values[1] - f.values[0]
+	d2 := f.values[2] - 2*f.values[1] + f.values[0]
+	return f.values[0] + t*d1 + t*(t-1)/2*d2, nil
+}
+
+// Tiled counts where the elf can end up after exactly steps steps on the
+// tiled garden. Short walks are searched outright; longer ones extrapolated
+// from a Fit.
+func (g *Garden) Tiled(steps int) (int, error) {
+	p := g.Period()
+	if steps/p <= 4 {
+		return g.Brute(steps), nil
+	}
+	fit, err := g.Fit(steps % p)
+	if err != nil {
+		return 0, err
+	}
+	return fit.At(steps)
+}
+
+// Check compares fitted counts with a brute force search for each of steps,
+// which should be small enough for the search yet past the fit's warmup.
+func (g *Garden) Check(steps ...int) error {
+	for _, n := range steps {
+		fit, err := g.Fit(n % g.Period())
+		if err != nil {
+			return err
+		}
+		fitted, err := fit.At(n)
+		if err != nil {
+			return err
+		}
+		if brute := g.Brute(n); fitted != brute {
+			return fmt.Errorf("garden: fit gives %d plots after %d steps, search finds %d", fitted, n, brute)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package garden
+
+import "fmt"
+
+// Garden is a map of plots ('.') and rocks ('#'), with the elf starting on
+// 'S'. Tiled, it repeats forever in every direction.
+type Garden struct {
+	Width, Height  int
+	StartX, StartY int
+	rock           [][]bool
+}
+
+func Parse(input []string) (*Garden, error) {
+	if len(input) == 0 || len(input[0]) == 0 {
+		return nil, fmt.Errorf("garden: empty map")
+	}
+	g := &Garden{Width: len(input[0]), Height: len(input), StartX: -1}
+	g.rock = make([][]bool, g.Height)
+	for y, line := range input {
+		if len(line) != g.Width {
+			return nil, fmt.Errorf("garden: line %d is %d wide, not %d", y+1, len(line), g.Width)
+		}
+		g.rock[y] = make([]bool, g.Width)
+		for x, ch := range line {
+			switch ch {
+			case '#':
+				g.rock[y][x] = true
+			case 'S':
+				g.StartX, g.StartY = x, y
+			case '.':
+			default:
+				return nil, fmt.Errorf("garden: line %d: unknown cell %q", y+1, ch)
+			}
+		}
+	}
+	if g.StartX < 0 {
+		return nil, fmt.Errorf("garden: no start")
+	}
+	return g, nil
+}
+
+func (g *Garden) rockAt(x, y int) bool {
+	return g.rock[(y%g.Height+g.Height)%g.Height][(x%g.Width+g.Width)%g.Width]
+}
+
+// layers counts the plots first reached after each number of steps, up to
+// steps. The search covers a square wide enough for any of them, so with
+// tiled set it sees as much of the endless garden as it needs.
+func (g *Garden) layers(steps int, tiled bool) []int {
+	side := 2*steps + 1
+	seen := make([]bool, side*side)
+	at := func(x, y int) int { return (y-g.StartY+steps)*side + x - g.StartX + steps }
+
+	counts := make([]int, 0, steps+1)
+	frontier := [][2]int{{g.StartX, g.StartY}}
+	seen[at(g.StartX, g.StartY)] = true
+	for d := 0; d <= steps && len(frontier) > 0; d++ {
+		counts = append(counts, len(frontier))
+		next := make([][2]int, 0, len(frontier)+4)
+		for _, p := range frontier {
+			for _, n := range [4][2]int{{p[0], p[1] - 1}, {p[0] + 1, p[1]}, {p[0], p[1] + 1}, {p[0] - 1, p[1]}} {
+				x, y := n[0], n[1]
+				if !tiled && (x < 0 || y < 0 || x >= g.Width || y >= g.Height) {
+					continue
+				}
+				if d == steps || g.rockAt(x, y) || seen[at(x, y)] {
+					continue
+				}
+				seen[at(x, y)] = true
+				next = append(next, n)
+			}
+		}
+		frontier = next
+	}
+	return counts
+}
+
+// ending turns layer counts into the number of plots the elf could end on
+// after exactly n steps: those reached in n steps or fewer with the same
+// parity, since it can step back and forth to use up the rest.
+func ending(counts []int, n int) int {
+	total := 0
+	for d := n % 2; d <= n && d < len(counts); d += 2 {
+		total += counts[d]
+	}
+	return total
+}
+
+// Reachable counts where the elf can end up after exactly steps steps
+// without leaving the map.
+func (g *Garden) Reachable(steps int) int {
+	return ending(g.layers(steps, false), steps)
+}
+
+// Brute counts where the elf can end up after exactly steps steps on the
+// tiled garden, by searching every plot within reach. It takes time and
+// memory quadratic in steps.
+func (g *Garden) Brute(steps int) int {
+	return ending(g.layers(steps, true), steps)
+}
@@ -0,0 +1,60 @@
+package garden
+
+import "testing"
+
+var sample = []string{
+	"...........",
+	".....###.#.",
+	".###.##..#.",
+	"..#.#...#..",
+	"....#.#....",
+	".##..S####.",
+	".##..#...#.",
+	".......##..",
+	".##.#.####.",
+	".##..##.##.",
+	"...........",
+}
+
+func TestReachable(t *testing.T) {
+	g, err := Parse(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Reachable(6); got != 16 {
+		t.Errorf("Reachable(6) = %d, want 16", got)
+	}
+}
+
+func TestTiled(t *testing.T) {
+	g, err := Parse(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct{ steps, want int }{
+		{6, 16},
+		{10, 50},
+		{50, 1594},
+		{100, 6536},
+		{500, 167004},
+		{1000, 668697},
+		{5000, 16733044},
+	} {
+		got, err := g.Tiled(tc.steps)
+		if err != nil {
+			t.Errorf("Tiled(%d): %v", tc.steps, err)
+		} else if got != tc.want {
+			t.Errorf("Tiled(%d) = %d, want %d", tc.steps, got, tc.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	g, err := Parse(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Check(500, 1000); err != nil {
+		t.Error(err)
+	}
+}
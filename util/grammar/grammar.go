@@ -0,0 +1,338 @@
+package grammar
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule is either a literal terminal or a list of alternative sequences of
+// other rule IDs, as in "0: 4 1 5" or "8: 42 | 42 8" or `4: "a"`.
+type Rule struct {
+	ID      int
+	Alts    [][]int
+	Literal string
+}
+
+func (r *Rule) String() string {
+	if r.Literal != "" {
+		return fmt.Sprintf("%d: %q", r.ID, r.Literal)
+	}
+
+	alts := make([]string, len(r.Alts))
+	for i, alt := range r.Alts {
+		ids := make([]string, len(alt))
+		for j, id := range alt {
+			ids[j] = strconv.Itoa(id)
+		}
+		alts[i] = strings.Join(ids, " ")
+	}
+	return fmt.Sprintf("%d: %s", r.ID, strings.Join(alts, " | "))
+}
+
+func ParseRule(line string) (*Rule, error) {
+	before, after, found := strings.Cut(line, ":")
+	if !found {
+		return nil, fmt.Errorf("rule %q: missing ':'", line)
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(before))
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %w", line, err)
+	}
+
+	body := strings.TrimSpace(after)
+	if strings.HasPrefix(body, "\"") {
+		literal, err := strconv.Unquote(body)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", line, err)
+		}
+		if literal == "" {
+			return nil, fmt.Errorf("rule %q: empty literal", line)
+		}
+		return &Rule{ID: id, Literal: literal}, nil
+	}
+
+	r := &Rule{ID: id}
+	for _, altStr := range strings.Split(body, "|") {
+		fields := strings.Fields(altStr)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("rule %q: empty alternative", line)
+		}
+
+		alt := make([]int, len(fields))
+		for i, f := range fields {
+			if alt[i], err = strconv.Atoi(f); err != nil {
+				return nil, fmt.Errorf("rule %q: %w", line, err)
+			}
+		}
+		r.Alts = append(r.Alts, alt)
+	}
+
+	return r, nil
+}
+
+type Grammar map[int]*Rule
+
+// Parse reads rule lines up to the first blank line, returning the grammar
+// and the number of lines consumed, blank line included.
+func Parse(input []string) (Grammar, int, error) {
+	g := make(Grammar)
+	for i, line := range input {
+		if line == "" {
+			return g, i + 1, nil
+		}
+		if err := g.Set(line); err != nil {
+			return nil, i, err
+		}
+	}
+	return g, len(input), nil
+}
+
+// Set adds or replaces the rule described by line.
+func (g Grammar) Set(line string) error {
+	r, err := ParseRule(line)
+	if err != nil {
+		return err
+	}
+	g[r.ID] = r
+	return nil
+}
+
+// Validate checks that every rule referenced is defined. Match does not, so
+// call it once after building or changing a grammar.
+func (g Grammar) Validate() error {
+	for _, r := range g {
+		for _, alt := range r.Alts {
+			for _, id := range alt {
+				if _, found := g[id]; !found {
+					return fmt.Errorf("rule %d references undefined rule %d", r.ID, id)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Node is a parse tree node covering message[Start:End] through rule Rule.
+// Literal rules are leaves.
+type Node struct {
+	Rule       int
+	Start, End int
+	Children   []*Node
+}
+
+func (n *Node) String() string {
+	var b strings.Builder
+	n.write(&b)
+	return b.String()
+}
+
+func (n *Node) write(b *strings.Builder) {
+	fmt.Fprintf(b, "(%d", n.Rule)
+	for _, c := range n.Children {
+		b.WriteByte(' ')
+		c.write(b)
+	}
+	b.WriteByte(')')
+}
+
+// MatchError is returned when a message is not in the language. Matched is
+// the length of the longest prefix of the message that some derivation of
+// the start rule could still extend.
+type MatchError struct {
+	Message string
+	Matched int
+}
+
+func (e *MatchError) Error() string {
+	return fmt.Sprintf("no match for %q: longest viable prefix %q", e.Message, e.Message[:e.Matched])
+}
+
+// Matches reports whether message is in the language. Errors other than a
+// failed match, such as an undefined start rule, are returned as they are.
+func (g Grammar) Matches(start int, message string) (bool, error) {
+	_, err := g.Match(start, message)
+	var mismatch *MatchError
+	if errors.As(err, &mismatch) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Match runs an Earley parse of message from the start rule, which copes with
+// any recursion in the rules, left or right. Undefined rules other than the
+// start rule match nothing; run Validate first to catch them.
+func (g Grammar) Match(start int, message string) (*Node, error) {
+	if _, found := g[start]; !found {
+		return nil, fmt.Errorf("start rule %d undefined", start)
+	}
+
+	p := newParser(g, message)
+	p.run(start)
+
+	if !p.completed[span{start, 0, len(message)}] {
+		return nil, &MatchError{message, p.furthest}
+	}
+	return p.build(start, 0, len(message)), nil
+}
+
+type item struct {
+	rule, alt, dot, origin int
+}
+
+type span struct {
+	rule, start, end int
+}
+
+type parser struct {
+	g         Grammar
+	message   string
+	chart     [][]item
+	seen      []map[item]bool
+	completed map[span]bool
+	furthest  int
+
+	building map[span]bool
+	nodes    map[span]*Node
+}
+
+func newParser(g Grammar, message string) *parser {
+	p := &parser{
+		g:         g,
+		message:   message,
+		chart:     make([][]item, len(message)+1),
+		seen:      make([]map[item]bool, len(message)+1),
+		completed: make(map[span]bool),
+		building:  make(map[span]bool),
+		nodes:     make(map[span]*Node),
+	}
+	for i := range p.seen {
+		p.seen[i] = make(map[item]bool)
+	}
+	return p
+}
+
+func (p *parser) add(pos int, it item) {
+	if !p.seen[pos][it] {
+		p.seen[pos][it] = true
+		p.chart[pos] = append(p.chart[pos], it)
+	}
+}
+
+func (p *parser) scan(pos, rule int) {
+	lit := p.g[rule].Literal
+	if strings.HasPrefix(p.message[pos:], lit) {
+		p.completed[span{rule, pos, pos + len(lit)}] = true
+	}
+}
+
+func (p *parser) predict(pos, rule int) {
+	r, found := p.g[rule]
+	if !found {
+		return
+	}
+	if r.Literal != "" {
+		p.scan(pos, rule)
+		return
+	}
+	for a := range r.Alts {
+		p.add(pos, item{rule, a, 0, pos})
+	}
+}
+
+func (p *parser) run(start int) {
+	p.predict(0, start)
+
+	for pos := range p.chart {
+		if len(p.chart[pos]) > 0 || p.completed[span{start, 0, pos}] {
+			p.furthest = pos
+		}
+
+		for i := 0; i < len(p.chart[pos]); i++ {
+			it := p.chart[pos][i]
+			alt := p.g[it.rule].Alts[it.alt]
+
+			if it.dot == len(alt) {
+				p.completed[span{it.rule, it.origin, pos}] = true
+				for _, waiting := range p.chart[it.origin] {
+					wAlt := p.g[waiting.rule].Alts[waiting.alt]
+					if waiting.dot < len(wAlt) && wAlt[waiting.dot] == it.rule {
+						p.add(pos, item{waiting.rule, waiting.alt, waiting.dot + 1, waiting.origin})
+					}
+				}
+				continue
+			}
+
+			next := alt[it.dot]
+			r, found := p.g[next]
+			if !found {
+				continue
+			}
+			if lit := r.Literal; lit != "" {
+				p.scan(pos, next)
+				if p.completed[span{next, pos, pos + len(lit)}] {
+					p.add(pos+len(lit), item{it.rule, it.alt, it.dot + 1, it.origin})
+				}
+			} else {
+				p.predict(pos, next)
+			}
+		}
+	}
+}
+
+// build reconstructs one derivation of rule over message[start:end] from the
+// completed spans recorded by run.
+func (p *parser) build(rule, start, end int) *Node {
+	s := span{rule, start, end}
+	if n, found := p.nodes[s]; found {
+		return n
+	}
+	if !p.completed[s] || p.building[s] {
+		return nil
+	}
+
+	r := p.g[rule]
+	if r.Literal != "" {
+		n := &Node{Rule: rule, Start: start, End: end}
+		p.nodes[s] = n
+		return n
+	}
+
+	p.building[s] = true
+	defer delete(p.building, s)
+
+	for _, alt := range r.Alts {
+		if children := p.fit(alt, start, end); children != nil {
+			n := &Node{Rule: rule, Start: start, End: end, Children: children}
+			p.nodes[s] = n
+			return n
+		}
+	}
+	return nil
+}
+
+func (p *parser) fit(alt []int, start, end int) []*Node {
+	if len(alt) == 1 {
+		if n := p.build(alt[0], start, end); n != nil {
+			return []*Node{n}
+		}
+		return nil
+	}
+
+	// Every rule consumes at least one byte, so leave room for the rest
+	for mid := start + 1; mid <= end-(len(alt)-1); mid++ {
+		if !p.completed[span{alt[0], start, mid}] {
+			continue
+		}
+		head := p.build(alt[0], start, mid)
+		if head == nil {
+			continue
+		}
+		if rest := p.fit(alt[1:], mid, end); rest != nil {
+			return append([]*Node{head}, rest...)
+		}
+	}
+	return nil
+}
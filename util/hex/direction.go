@@ -0,0 +1,62 @@
+package hex
+
+import "fmt"
+
+// Direction is one of the six neighbours of a pointy-topped hex, in
+// anticlockwise order from east.
+type Direction int
+
+const (
+	E Direction = iota
+	NE
+	NW
+	W
+	SW
+	SE
+)
+
+var (
+	offsets = [6]Hex{{1, 0}, {1, -1}, {0, -1}, {-1, 0}, {-1, 1}, {0, 1}}
+	names   = [6]string{"e", "ne", "nw", "w", "sw", "se"}
+)
+
+func (d Direction) Offset() Hex         { return offsets[d] }
+func (d Direction) String() string      { return names[d] }
+func (d Direction) Opposite() Direction { return (d + 3) % 6 }
+
+// Turn rotates d by steps sixths of a turn clockwise, or anticlockwise for
+// negative steps.
+func (d Direction) Turn(steps int) Direction {
+	return Direction(((int(d)-steps)%6 + 6) % 6)
+}
+
+func ParseDirection(s string) (Direction, error) {
+	for d, name := range names {
+		if s == name {
+			return Direction(d), nil
+		}
+	}
+	return 0, fmt.Errorf("hex: unknown direction %q", s)
+}
+
+// ParsePath reads directions written without separators, such as "esenee".
+func ParsePath(s string) ([]Direction, error) {
+	path := make([]Direction, 0, len(s))
+	for start := 0; start < len(s); {
+		end := start + 1
+		if s[start] == 'n' || s[start] == 's' {
+			end++
+		}
+		if end > len(s) {
+			return nil, fmt.Errorf("hex: path %q ends mid-direction", s)
+		}
+
+		d, err := ParseDirection(s[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("hex: path %q has unknown direction %q at %d", s, s[start:end], start)
+		}
+		path = append(path, d)
+		start = end
+	}
+	return path, nil
+}
@@ -0,0 +1,152 @@
+package hex
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hex is a cell of a pointy-topped hexagonal grid in axial coordinates: Q
+// runs east and R south-east. The implied third cube coordinate is S.
+type Hex struct {
+	Q, R int
+}
+
+func FromCube(q, r, s int) (Hex, error) {
+	if q+r+s != 0 {
+		return Hex{}, fmt.Errorf("hex: cube coordinates %d,%d,%d do not sum to zero", q, r, s)
+	}
+	return Hex{q, r}, nil
+}
+
+func (h Hex) S() int { return -h.Q - h.R }
+
+func (h Hex) Cube() (q, r, s int) { return h.Q, h.R, h.S() }
+
+func (h Hex) Add(o Hex) Hex        { return Hex{h.Q + o.Q, h.R + o.R} }
+func (h Hex) Sub(o Hex) Hex        { return Hex{h.Q - o.Q, h.R - o.R} }
+func (h Hex) Scale(k int) Hex      { return Hex{h.Q * k, h.R * k} }
+func (h Hex) String() string       { return fmt.Sprintf("(%d,%d)", h.Q, h.R) }
+func (h Hex) Step(d Direction) Hex { return h.Add(d.Offset()) }
+
+// Move goes n steps in one direction.
+func (h Hex) Move(d Direction, n int) Hex { return h.Add(d.Offset().Scale(n)) }
+
+// Walk follows a path of directions from h.
+func (h Hex) Walk(path []Direction) Hex {
+	for _, d := range path {
+		h = h.Step(d)
+	}
+	return h
+}
+
+// Neighbours are in Direction order.
+func (h Hex) Neighbours() [6]Hex {
+	var ns [6]Hex
+	for d := range offsets {
+		ns[d] = h.Add(offsets[d])
+	}
+	return ns
+}
+
+// Length is the number of steps from the origin to h.
+func (h Hex) Length() int {
+	return (abs(h.Q) + abs(h.R) + abs(h.S())) / 2
+}
+
+func Distance(a, b Hex) int { return a.Sub(b).Length() }
+
+// Rotate turns h about the origin by steps sixths of a turn clockwise, or
+// anticlockwise for negative steps.
+func (h Hex) Rotate(steps int) Hex {
+	q, r, s := h.Cube()
+	for i := 0; i < ((steps%6)+6)%6; i++ {
+		q, r, s = -r, -s, -q
+	}
+	return Hex{q, r}
+}
+
+func (h Hex) RotateAround(centre Hex, steps int) Hex {
+	return h.Sub(centre).Rotate(steps).Add(centre)
+}
+
+// Ring lists the 6*radius cells at exactly radius steps from h, clockwise
+// from the one due west.
+func (h Hex) Ring(radius int) []Hex {
+	if radius == 0 {
+		return []Hex{h}
+	}
+
+	ring := make([]Hex, 0, 6*radius)
+	cell := h.Move(W, radius)
+	for _, d := range []Direction{NE, E, SE, SW, W, NW} {
+		for i := 0; i < radius; i++ {
+			ring = append(ring, cell)
+			cell = cell.Step(d)
+		}
+	}
+	return ring
+}
+
+// Spiral lists every cell within radius steps of h, ring by ring outwards.
+func (h Hex) Spiral(radius int) []Hex {
+	cells := make([]Hex, 0, 1+3*radius*(radius+1))
+	for r := 0; r <= radius; r++ {
+		cells = append(cells, h.Ring(r)...)
+	}
+	return cells
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Render draws cells as text, each row of hexes offset half a cell from the
+// one above. Hexes missing from cells, inside the drawing's bounds, are
+// drawn as fill.
+func Render(cells map[Hex]byte, fill byte) string {
+	if len(cells) == 0 {
+		return ""
+	}
+
+	// A hex sits at column 2Q+R of row R
+	first := true
+	var minX, maxX, minR, maxR int
+	for h := range cells {
+		x := 2*h.Q + h.R
+		if first {
+			minX, maxX, minR, maxR = x, x, h.R, h.R
+			first = false
+			continue
+		}
+		if x < minX {
+			minX = x
+		} else if x > maxX {
+			maxX = x
+		}
+		if h.R < minR {
+			minR = h.R
+		} else if h.R > maxR {
+			maxR = h.R
+		}
+	}
+
+	var b strings.Builder
+	for r := minR; r <= maxR; r++ {
+		var line strings.Builder
+		for x := minX; x <= maxX; x++ {
+			if abs(x-r)%2 == 1 {
+				line.WriteByte(' ')
+			} else if c, ok := cells[Hex{(x - r) / 2, r}]; ok {
+				line.WriteByte(c)
+			} else {
+				line.WriteByte(fill)
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package jigsaw
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Grid is a rectangle of characters, row by row.
+type Grid struct {
+	Rows, Cols int
+	Cells      []byte
+}
+
+func NewGrid(rows, cols int, fill byte) Grid {
+	g := Grid{rows, cols, make([]byte, rows*cols)}
+	for i := range g.Cells {
+		g.Cells[i] = fill
+	}
+	return g
+}
+
+// ParseGrid reads equally long lines.
+func ParseGrid(lines []string) (Grid, error) {
+	if len(lines) == 0 {
+		return Grid{}, fmt.Errorf("jigsaw: empty grid")
+	}
+	g := Grid{Rows: len(lines), Cols: len(lines[0]), Cells: make([]byte, 0, len(lines)*len(lines[0]))}
+	for i, line := range lines {
+		if len(line) != g.Cols {
+			return Grid{}, fmt.Errorf("jigsaw: grid row %d is %d wide, not %d", i+1, len(line), g.Cols)
+		}
+		g.Cells = append(g.Cells, line...)
+	}
+	return g, nil
+}
+
+func (g Grid) At(r, c int) byte     { return g.Cells[r*g.Cols+c] }
+func (g Grid) Set(r, c int, b byte) { g.Cells[r*g.Cols+c] = b }
+func (g Grid) Row(r int) string     { return string(g.Cells[r*g.Cols : (r+1)*g.Cols]) }
+func (g Grid) Count(b byte) int     { return strings.Count(string(g.Cells), string(b)) }
+func (g Grid) Clone() Grid          { return Grid{g.Rows, g.Cols, append([]byte{}, g.Cells...)} }
+
+func (g Grid) Col(c int) string {
+	col := make([]byte, g.Rows)
+	for r := range col {
+		col[r] = g.At(r, c)
+	}
+	return string(col)
+}
+
+func (g Grid) Equal(o Grid) bool {
+	return g.Rows == o.Rows && g.Cols == o.Cols && string(g.Cells) == string(o.Cells)
+}
+
+func (g Grid) String() string {
+	var b strings.Builder
+	for r := 0; r < g.Rows; r++ {
+		b.WriteString(g.Row(r))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Orientation is one of the eight symmetries of a square, the dihedral group
+// D4: mirror left to right if Flip, then turn clockwise Turns quarter turns.
+type Orientation struct {
+	Flip  bool
+	Turns int
+}
+
+// Orientations lists all of D4, the identity first.
+var Orientations = func() [8]Orientation {
+	var os [8]Orientation
+	for i := range os {
+		os[i] = Orientation{Flip: i >= 4, Turns: i % 4}
+	}
+	return os
+}()
+
+func (o Orientation) String() string {
+	s := fmt.Sprintf("r%d", o.Turns*90)
+	if o.Flip {
+		s = "flip+" + s
+	}
+	return s
+}
+
+// place finds where cell r, c of a rows by cols grid lands.
+func (o Orientation) place(r, c, rows, cols int) (int, int) {
+	if o.Flip {
+		c = cols - 1 - c
+	}
+	for i := 0; i < o.Turns; i++ {
+		r, c = c, rows-1-r
+		rows, cols = cols, rows
+	}
+	return r, c
+}
+
+// Orient returns a copy of g turned to o.
+func (g Grid) Orient(o Orientation) Grid {
+	out := Grid{g.Rows, g.Cols, make([]byte, len(g.Cells))}
+	if o.Turns%2 == 1 {
+		out.Rows, out.Cols = g.Cols, g.Rows
+	}
+	for r := 0; r < g.Rows; r++ {
+		for c := 0; c < g.Cols; c++ {
+			nr, nc := o.place(r, c, g.Rows, g.Cols)
+			out.Set(nr, nc, g.At(r, c))
+		}
+	}
+	return out
+}
+
+// Then is the orientation that applies o, then p.
+func (o Orientation) Then(p Orientation) Orientation {
+	return thenTable[o.index()][p.index()]
+}
+
+func (o Orientation) index() int {
+	i := o.Turns % 4
+	if o.Flip {
+		i += 4
+	}
+	return i
+}
+
+var thenTable = func() [8][8]Orientation {
+	probe := Grid{3, 3, []byte("abcdefghi")}
+	var table [8][8]Orientation
+	for _, a := range Orientations {
+		for _, b := range Orientations {
+			both := probe.Orient(a).Orient(b)
+			for _, c := range Orientations {
+				if probe.Orient(c).Equal(both) {
+					table[a.index()][b.index()] = c
+				}
+			}
+		}
+	}
+	return table
+}()
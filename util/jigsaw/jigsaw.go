@@ -0,0 +1,262 @@
+package jigsaw
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Tile struct {
+	ID int
+	Grid
+}
+
+// ParseTiles reads blocks of a "Tile N:" header followed by the tile's rows,
+// separated by blank lines.
+func ParseTiles(input []string) ([]Tile, error) {
+	tiles := make([]Tile, 0)
+	for start := 0; start < len(input); {
+		if input[start] == "" {
+			start++
+			continue
+		}
+
+		var t Tile
+		if _, err := fmt.Sscanf(input[start], "Tile %d:", &t.ID); err != nil {
+			return nil, fmt.Errorf("jigsaw: line %d %q: expected a tile header", start+1, input[start])
+		}
+		end := start + 1
+		for end < len(input) && input[end] != "" {
+			end++
+		}
+
+		var err error
+		if t.Grid, err = ParseGrid(input[start+1 : end]); err != nil {
+			return nil, fmt.Errorf("tile %d: %w", t.ID, err)
+		}
+		tiles = append(tiles, t)
+		start = end
+	}
+	return tiles, nil
+}
+
+// Placement is a tile set into the puzzle, turned to Orientation.
+type Placement struct {
+	ID int
+	Orientation
+}
+
+// Assembly is a solved puzzle: Layout holds Side by Side placements.
+type Assembly struct {
+	Side   int
+	Layout [][]Placement
+	tiles  map[int]Grid
+}
+
+var ErrUnsolvable = errors.New("jigsaw: tiles do not fit together")
+
+// AmbiguousError reports two different ways to assemble the same tiles.
+type AmbiguousError struct {
+	First, Second *Assembly
+}
+
+func (e *AmbiguousError) Error() string {
+	for r, row := range e.First.Layout {
+		for c, p := range row {
+			if q := e.Second.Layout[r][c]; p != q {
+				return fmt.Sprintf("jigsaw: tiles fit together in more than one way: position %d,%d holds %d %v or %d %v",
+					r, c, p.ID, p.Orientation, q.ID, q.Orientation)
+			}
+		}
+	}
+	return "jigsaw: tiles fit together in more than one way"
+}
+
+// candidate is a tile turned one way, with its edges read left to right and
+// top to bottom.
+type candidate struct {
+	tile                     int
+	orientation              Orientation
+	top, right, bottom, left string
+}
+
+// Assemble lays square tiles of the same size in a square so that touching
+// edges match. Turning or flipping the whole puzzle gives the same picture,
+// so solutions differing only that way count as one; any other second
+// solution is an AmbiguousError.
+func Assemble(tiles []Tile) (*Assembly, error) {
+	side := 0
+	for side*side < len(tiles) {
+		side++
+	}
+	if len(tiles) == 0 || side*side != len(tiles) {
+		return nil, fmt.Errorf("jigsaw: %d tiles cannot make a square", len(tiles))
+	}
+
+	grids := make(map[int]Grid)
+	for _, t := range tiles {
+		if t.Rows != t.Cols || t.Rows != tiles[0].Rows {
+			return nil, fmt.Errorf("jigsaw: tile %d is %dx%d, not %dx%d", t.ID, t.Rows, t.Cols, tiles[0].Rows, tiles[0].Rows)
+		}
+		if _, ok := grids[t.ID]; ok {
+			return nil, fmt.Errorf("jigsaw: tile %d appears twice", t.ID)
+		}
+		grids[t.ID] = t.Grid
+	}
+
+	cands := make([]candidate, 0, 8*len(tiles))
+	byLeft, byTop := make(map[string][]int), make(map[string][]int)
+	for i, t := range tiles {
+		for _, o := range Orientations {
+			g := t.Orient(o)
+			byLeft[g.Col(0)] = append(byLeft[g.Col(0)], len(cands))
+			byTop[g.Row(0)] = append(byTop[g.Row(0)], len(cands))
+			cands = append(cands, candidate{i, o, g.Row(0), g.Col(g.Cols - 1), g.Row(g.Rows - 1), g.Col(0)})
+		}
+	}
+
+	s := &search{side: side, tiles: tiles, cands: cands, byLeft: byLeft, byTop: byTop,
+		used: make([]bool, len(tiles)), grid: make([]int, len(tiles)), seen: make(map[string]bool)}
+	s.fill(0)
+
+	switch len(s.found) {
+	case 0:
+		return nil, ErrUnsolvable
+	case 1:
+		return s.assembly(s.found[0], grids), nil
+	default:
+		return nil, &AmbiguousError{s.assembly(s.found[0], grids), s.assembly(s.found[1], grids)}
+	}
+}
+
+type search struct {
+	side   int
+	tiles  []Tile
+	cands  []candidate
+	byLeft map[string][]int // candidate indices by left edge
+	byTop  map[string][]int
+
+	used  []bool
+	grid  []int // candidate index by position
+	seen  map[string]bool
+	found [][]Placement // distinct solutions, up to two
+}
+
+func (s *search) fill(pos int) {
+	if len(s.found) >= 2 {
+		return
+	}
+	if pos == len(s.grid) {
+		s.record()
+		return
+	}
+
+	r, c := pos/s.side, pos%s.side
+	var options []int
+	switch {
+	case c > 0:
+		options = s.byLeft[s.cands[s.grid[pos-1]].right]
+	case r > 0:
+		options = s.byTop[s.cands[s.grid[pos-s.side]].bottom]
+	default:
+		options = make([]int, len(s.cands))
+		for i := range options {
+			options[i] = i
+		}
+	}
+
+	for _, i := range options {
+		cand := s.cands[i]
+		if s.used[cand.tile] || (r > 0 && s.cands[s.grid[pos-s.side]].bottom != cand.top) {
+			continue
+		}
+		s.used[cand.tile], s.grid[pos] = true, i
+		s.fill(pos + 1)
+		s.used[cand.tile] = false
+	}
+}
+
+// record keeps a solution unless it is a turned or flipped copy of one
+// already found.
+func (s *search) record() {
+	layout := make([]Placement, len(s.grid))
+	for pos, i := range s.grid {
+		layout[pos] = Placement{s.tiles[s.cands[i].tile].ID, s.cands[i].orientation}
+	}
+
+	keys := make([]string, 0, len(Orientations))
+	for _, o := range Orientations {
+		keys = append(keys, s.key(layout, o))
+	}
+	sort.Strings(keys)
+	if !s.seen[keys[0]] {
+		s.seen[keys[0]] = true
+		s.found = append(s.found, layout)
+	}
+}
+
+// key describes layout as it looks after turning the whole puzzle to o.
+func (s *search) key(layout []Placement, o Orientation) string {
+	turned := make([]Placement, len(layout))
+	for pos, p := range layout {
+		r, c := o.place(pos/s.side, pos%s.side, s.side, s.side)
+		turned[r*s.side+c] = Placement{p.ID, p.Orientation.Then(o)}
+	}
+
+	var b strings.Builder
+	for _, p := range turned {
+		fmt.Fprintf(&b, "%d%v,", p.ID, p.Orientation)
+	}
+	return b.String()
+}
+
+func (s *search) assembly(layout []Placement, grids map[int]Grid) *Assembly {
+	a := &Assembly{Side: s.side, Layout: make([][]Placement, s.side), tiles: grids}
+	for r := range a.Layout {
+		a.Layout[r] = layout[r*s.side : (r+1)*s.side]
+	}
+	return a
+}
+
+// Corners lists the corner tile IDs clockwise from the top left.
+func (a *Assembly) Corners() [4]int {
+	n := a.Side - 1
+	return [4]int{a.Layout[0][0].ID, a.Layout[0][n].ID, a.Layout[n][n].ID, a.Layout[n][0].ID}
+}
+
+// Image joins the placed tiles into one grid after trimming border cells off
+// every side of each.
+func (a *Assembly) Image(border int) Grid {
+	var inner int
+	for _, g := range a.tiles {
+		inner = g.Rows - 2*border
+		break
+	}
+
+	image := NewGrid(a.Side*inner, a.Side*inner, ' ')
+	for r, row := range a.Layout {
+		for c, p := range row {
+			g := a.tiles[p.ID].Orient(p.Orientation)
+			for i := 0; i < inner; i++ {
+				for j := 0; j < inner; j++ {
+					image.Set(r*inner+i, c*inner+j, g.At(border+i, border+j))
+				}
+			}
+		}
+	}
+	return image
+}
+
+func (a *Assembly) String() string {
+	var b strings.Builder
+	for _, row := range a.Layout {
+		cells := make([]string, len(row))
+		for i, p := range row {
+			cells[i] = fmt.Sprintf("%d %-9v", p.ID, p.Orientation)
+		}
+		b.WriteString(strings.TrimRight(strings.Join(cells, "  "), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package jigsaw
+
+// Match is where a pattern, turned to Orientation, sits in an image with its
+// top left corner at Row, Col.
+type Match struct {
+	Row, Col int
+	Orientation
+	pattern Grid
+}
+
+// Find looks for pattern in g in all eight orientations. Spaces in the
+// pattern match anything; every other cell must match exactly. Matches may
+// overlap.
+func (g Grid) Find(pattern Grid) []Match {
+	matches := make([]Match, 0)
+	seen := make(map[string]bool)
+	for _, o := range Orientations {
+		p := pattern.Orient(o)
+
+		// A symmetric pattern looks the same in several orientations
+		if seen[p.String()] {
+			continue
+		}
+		seen[p.String()] = true
+
+		for r := 0; r+p.Rows <= g.Rows; r++ {
+			for c := 0; c+p.Cols <= g.Cols; c++ {
+				if g.matchAt(p, r, c) {
+					matches = append(matches, Match{r, c, o, p})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+func (g Grid) matchAt(p Grid, r, c int) bool {
+	for i := 0; i < p.Rows; i++ {
+		for j := 0; j < p.Cols; j++ {
+			if b := p.At(i, j); b != ' ' && g.At(r+i, c+j) != b {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Mark returns a copy of g with every cell any match covers set to mark.
+func (g Grid) Mark(matches []Match, mark byte) Grid {
+	marked := g.Clone()
+	for _, m := range matches {
+		for i := 0; i < m.pattern.Rows; i++ {
+			for j := 0; j < m.pattern.Cols; j++ {
+				if m.pattern.At(i, j) != ' ' {
+					marked.Set(m.Row+i, m.Col+j, mark)
+				}
+			}
+		}
+	}
+	return marked
+}
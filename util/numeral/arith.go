@@ -0,0 +1,157 @@
+package numeral
+
+// normalize carries a vector of arbitrary digit values, least significant
+// first, back into valid digits. For systems without negative digits the
+// value must not be negative.
+func (s *System) normalize(vals []int) []int {
+	if s.Base == 1 {
+		total := 0
+		for _, v := range vals {
+			total += v
+		}
+		out := make([]int, total)
+		for i := range out {
+			out[i] = 1
+		}
+		return out
+	}
+
+	// Carry with a zero digit available, so a zero remainder stays zero
+	min := s.Min
+	if min > 0 {
+		min = 0
+	}
+
+	out := make([]int, 0, len(vals)+1)
+	carry := 0
+	for i := 0; carry != 0 || !allZeroFrom(vals, i); i++ {
+		sum := carry
+		if i < len(vals) {
+			sum += vals[i]
+		}
+		d := mod(sum-min, s.Base) + min
+		out = append(out, d)
+		carry = (sum - d) / s.Base
+	}
+
+	// Bijective systems have no zero digit, so borrow from the next place
+	out = trim(out)
+	if s.Min > 0 {
+		for i := 0; i+1 < len(out); i++ {
+			if out[i] <= 0 {
+				out[i] += s.Base
+				out[i+1]--
+			}
+		}
+	}
+	return trim(out)
+}
+
+func allZeroFrom(vals []int, from int) bool {
+	if from >= len(vals) {
+		return true
+	}
+	for _, v := range vals[from:] {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// compareMagnitude compares canonical unsigned digit vectors. Longer is
+// larger in both standard and bijective systems.
+func compareMagnitude(a, b []int) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Add sums two numerals digit by digit, carrying within the system, without
+// converting to an integer.
+func (s *System) Add(a, b string) (string, error) {
+	negA, valsA, err := s.parse(a)
+	if err != nil {
+		return "", err
+	}
+	negB, valsB, err := s.parse(b)
+	if err != nil {
+		return "", err
+	}
+
+	// Balanced systems absorb negatives into the digits
+	if s.signed() || negA == negB {
+		return s.format(negA, s.normalize(add(valsA, valsB, 1))), nil
+	}
+
+	valsA, valsB = trim(valsA), trim(valsB)
+	switch compareMagnitude(valsA, valsB) {
+	case 0:
+		return s.Zero(), nil
+	case 1:
+		return s.format(negA, s.normalize(add(valsA, valsB, -1))), nil
+	default:
+		return s.format(negB, s.normalize(add(valsB, valsA, -1))), nil
+	}
+}
+
+// add returns a + sign*b digit by digit, without carrying.
+func add(a, b []int, sign int) []int {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	out := make([]int, n)
+	copy(out, a)
+	for i, v := range b {
+		out[i] += sign * v
+	}
+	return out
+}
+
+func (s *System) Sum(nums []string) (string, error) {
+	sum := s.Zero()
+	for _, n := range nums {
+		var err error
+		if sum, err = s.Add(sum, n); err != nil {
+			return "", err
+		}
+	}
+	return sum, nil
+}
+
+// Negate flips the sign of a numeral.
+func (s *System) Negate(a string) (string, error) {
+	neg, vals, err := s.parse(a)
+	if err != nil {
+		return "", err
+	}
+	if !s.signed() {
+		return s.format(!neg, vals), nil
+	}
+
+	for i := range vals {
+		vals[i] = -vals[i]
+	}
+	return s.format(false, s.normalize(vals)), nil
+}
+
+func (s *System) Sub(a, b string) (string, error) {
+	negB, err := s.Negate(b)
+	if err != nil {
+		return "", err
+	}
+	return s.Add(a, negB)
+}
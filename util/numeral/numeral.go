@@ -0,0 +1,185 @@
+package numeral
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const sign = '-'
+
+var ErrOverflow = errors.New("numeral: value overflows int")
+
+// System is a positional numeral system whose digits, in order, stand for the
+// consecutive values Min, Min+1, ..., Min+Base-1.
+//
+// Min 0 gives standard bases, Min 1 bijective ones (where zero is written as
+// the empty string) and negative Min balanced ones such as SNAFU. Systems
+// without negative digits write negative numbers with a leading '-'.
+type System struct {
+	Base   int
+	Min    int
+	digits string
+	values [256]int // digit value + 1, or 0 for bytes that are not digits
+}
+
+var (
+	Binary  = MustNew("01", 0)
+	Decimal = MustNew("0123456789", 0)
+	Hex     = MustNew("0123456789abcdef", 0)
+	SNAFU   = MustNew("=-012", -2)
+)
+
+// New builds a system from single-byte digits. Min must be between
+// 2-len(digits) and 1 so that every integer has a representation.
+func New(digits string, min int) (*System, error) {
+	s := &System{Base: len(digits), Min: min, digits: digits}
+	if s.Base < 1 || (s.Base == 1 && min != 1) {
+		return nil, fmt.Errorf("numeral: base %d with min %d cannot write every number", s.Base, min)
+	}
+	if min > 1 || min+s.Base-1 < 1 {
+		return nil, fmt.Errorf("numeral: digits %d..%d cannot write every number", min, min+s.Base-1)
+	}
+
+	for i := 0; i < len(digits); i++ {
+		if s.values[digits[i]] != 0 {
+			return nil, fmt.Errorf("numeral: digit %q repeated", digits[i])
+		}
+		s.values[digits[i]] = i + 1
+	}
+	if !s.signed() && s.values[sign] != 0 {
+		return nil, fmt.Errorf("numeral: %q is a digit, so negative numbers cannot be written", sign)
+	}
+	return s, nil
+}
+
+func MustNew(digits string, min int) *System {
+	s, err := New(digits, min)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func Standard(digits string) (*System, error)  { return New(digits, 0) }
+func Bijective(digits string) (*System, error) { return New(digits, 1) }
+
+// Balanced centres the digit values on zero, e.g. "-0+" for balanced ternary.
+// Even bases get one more positive digit than negative.
+func Balanced(digits string) (*System, error) { return New(digits, -(len(digits)-1)/2) }
+
+func (s *System) Digits() string { return s.digits }
+
+// signed reports whether negative numbers are written with negative digits
+// rather than a sign.
+func (s *System) signed() bool { return s.Min < 0 }
+
+func (s *System) digit(v int) byte { return s.digits[v-s.Min] }
+
+func (s *System) Zero() string {
+	if s.Min > 0 {
+		return ""
+	}
+	return string(s.digit(0))
+}
+
+// parse returns the digit values of str, least significant first.
+func (s *System) parse(str string) (neg bool, vals []int, err error) {
+	if !s.signed() && len(str) > 0 && str[0] == sign {
+		neg, str = true, str[1:]
+	}
+
+	vals = make([]int, len(str))
+	for i := 0; i < len(str); i++ {
+		v := s.values[str[i]]
+		if v == 0 {
+			return false, nil, fmt.Errorf("numeral: %q is not a digit of %q", str[i], s.digits)
+		}
+		vals[len(str)-1-i] = v - 1 + s.Min
+	}
+	return neg, vals, nil
+}
+
+func (s *System) format(neg bool, vals []int) string {
+	vals = trim(vals)
+	if len(vals) == 0 {
+		return s.Zero()
+	}
+
+	var b strings.Builder
+	if neg {
+		b.WriteByte(sign)
+	}
+	for i := len(vals) - 1; i >= 0; i-- {
+		b.WriteByte(s.digit(vals[i]))
+	}
+	return b.String()
+}
+
+// trim drops leading zero digits.
+func trim(vals []int) []int {
+	for len(vals) > 0 && vals[len(vals)-1] == 0 {
+		vals = vals[:len(vals)-1]
+	}
+	return vals
+}
+
+// mod is a non-negative remainder.
+func mod(a, b int) int {
+	return ((a % b) + b) % b
+}
+
+func (s *System) DecodeBig(str string) (*big.Int, error) {
+	neg, vals, err := s.parse(str)
+	if err != nil {
+		return nil, err
+	}
+
+	n, base := new(big.Int), big.NewInt(int64(s.Base))
+	for i := len(vals) - 1; i >= 0; i-- {
+		n.Mul(n, base)
+		n.Add(n, big.NewInt(int64(vals[i])))
+	}
+	if neg {
+		n.Neg(n)
+	}
+	return n, nil
+}
+
+func (s *System) Decode(str string) (int, error) {
+	n, err := s.DecodeBig(str)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsInt64() || int64(int(n.Int64())) != n.Int64() {
+		return 0, ErrOverflow
+	}
+	return int(n.Int64()), nil
+}
+
+func (s *System) EncodeBig(n *big.Int) string {
+	neg := n.Sign() < 0 && !s.signed()
+	rest := new(big.Int).Set(n)
+	if neg {
+		rest.Neg(rest)
+	}
+
+	base, min := big.NewInt(int64(s.Base)), big.NewInt(int64(s.Min))
+	vals := make([]int, 0)
+	d := new(big.Int)
+	for rest.Sign() != 0 {
+		// Pick the digit congruent to rest, then shift it out
+		d.Sub(rest, min)
+		d.Mod(d, base)
+		d.Add(d, min)
+		vals = append(vals, int(d.Int64()))
+		rest.Sub(rest, d)
+		rest.Quo(rest, base)
+	}
+	return s.format(neg, vals)
+}
+
+func (s *System) Encode(n int) string {
+	return s.EncodeBig(big.NewInt(int64(n)))
+}
@@ -0,0 +1,141 @@
+package numeral
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+var systems = map[string]*System{
+	"binary":            Binary,
+	"decimal":           Decimal,
+	"hex":               Hex,
+	"SNAFU":             SNAFU,
+	"bijective base 26": MustNew("ABCDEFGHIJKLMNOPQRSTUVWXYZ", 1),
+	"balanced ternary":  MustNew("-0+", -1),
+	"balanced base 4":   MustNew("=-01", -2),
+	"unary":             MustNew("1", 1),
+}
+
+// randomNumeral writes a canonical numeral of up to length digits: no
+// leading zero digit, and a sign only on non-zero numbers.
+func randomNumeral(rng *rand.Rand, s *System, length int) string {
+	if rng.Intn(10) == 0 {
+		return s.Zero()
+	}
+	b := make([]byte, 0, length+1)
+	if !s.signed() && rng.Intn(2) == 0 {
+		b = append(b, sign)
+	}
+	for i := 1 + rng.Intn(length); i > 0; i-- {
+		v := s.Min + rng.Intn(s.Base)
+		for len(b) == 0 || b[len(b)-1] == sign {
+			if v != 0 {
+				break
+			}
+			v = s.Min + rng.Intn(s.Base)
+		}
+		b = append(b, s.digit(v))
+	}
+	return string(b)
+}
+
+// randomInt spreads its picks over every bit length, both signs.
+func randomInt(rng *rand.Rand) int {
+	n := int(rng.Uint64() >> rng.Intn(64))
+	if rng.Intn(2) == 0 {
+		n = -n
+	}
+	return n
+}
+
+func TestDecodeEncode(t *testing.T) {
+	rng := rand.New(rand.NewSource(25))
+	for name, s := range systems {
+		length := 64
+		if s.Base == 1 {
+			length = 200
+		}
+		for i := 0; i < 1000; i++ {
+			str := randomNumeral(rng, s, length)
+			n, err := s.DecodeBig(str)
+			if err != nil {
+				t.Fatalf("%s: DecodeBig(%q): %v", name, str, err)
+			}
+			if got := s.EncodeBig(n); got != str {
+				t.Fatalf("%s: %q decodes to %s, which encodes to %q", name, str, n, got)
+			}
+			if !n.IsInt64() {
+				continue
+			}
+			m, err := s.Decode(str)
+			if err != nil {
+				t.Fatalf("%s: Decode(%q): %v", name, str, err)
+			}
+			if got := s.Encode(m); got != str {
+				t.Fatalf("%s: %q decodes to %d, which encodes to %q", name, str, m, got)
+			}
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	rng := rand.New(rand.NewSource(25))
+	for name, s := range systems {
+		if s.Base == 1 {
+			for n := 0; n < 300; n++ {
+				if m, err := s.Decode(s.Encode(n)); err != nil || m != n {
+					t.Fatalf("%s: %d encodes to %q, which decodes to %d, %v", name, n, s.Encode(n), m, err)
+				}
+			}
+			continue
+		}
+
+		ns := []int{0, 1, -1, math.MaxInt, math.MinInt, math.MaxInt - 1, math.MinInt + 1}
+		for i := 0; i < 1000; i++ {
+			ns = append(ns, randomInt(rng))
+		}
+		for _, n := range ns {
+			str := s.Encode(n)
+			if m, err := s.Decode(str); err != nil || m != n {
+				t.Fatalf("%s: %d encodes to %q, which decodes to %d, %v", name, n, str, m, err)
+			}
+		}
+
+		for _, n := range []*big.Int{
+			new(big.Int).Add(big.NewInt(math.MaxInt), big.NewInt(1)),
+			new(big.Int).Sub(big.NewInt(math.MinInt), big.NewInt(1)),
+		} {
+			if _, err := s.Decode(s.EncodeBig(n)); err != ErrOverflow {
+				t.Errorf("%s: Decode of %s gave %v, want ErrOverflow", name, n, err)
+			}
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	rng := rand.New(rand.NewSource(25))
+	for name, s := range systems {
+		for i := 0; i < 1000; i++ {
+			a, b := randomInt(rng)>>2, randomInt(rng)>>2
+			if s.Base == 1 {
+				a, b = rng.Intn(100), rng.Intn(100)
+			}
+			sum, err := s.Add(s.Encode(a), s.Encode(b))
+			if err != nil {
+				t.Fatalf("%s: %d + %d: %v", name, a, b, err)
+			}
+			if want := s.Encode(a + b); sum != want {
+				t.Fatalf("%s: %d + %d = %q, want %q", name, a, b, sum, want)
+			}
+			diff, err := s.Sub(s.Encode(a), s.Encode(b))
+			if err != nil {
+				t.Fatalf("%s: %d - %d: %v", name, a, b, err)
+			}
+			if want := s.Encode(a - b); diff != want {
+				t.Fatalf("%s: %d - %d = %q, want %q", name, a, b, diff, want)
+			}
+		}
+	}
+}
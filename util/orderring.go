@@ -0,0 +1,172 @@
+package util
+
+import "math/rand"
+
+// OrderRing keeps the labels 0..n-1 in circular order, like Ring, but can
+// also find a label's position and move it by k places in O(log n). It is an
+// implicit treap keyed by position.
+type OrderRing struct {
+	left, right, parent, size []int
+	priority                  []int32
+	root                      int
+}
+
+const nilNode = -1
+
+// NewOrderRing builds a ring in the given order, which must be a permutation
+// of 0..len(order)-1.
+func NewOrderRing(order []int) *OrderRing {
+	n := len(order)
+	t := &OrderRing{
+		left:     make([]int, n),
+		right:    make([]int, n),
+		parent:   make([]int, n),
+		size:     make([]int, n),
+		priority: make([]int32, n),
+		root:     nilNode,
+	}
+
+	rng := rand.New(rand.NewSource(int64(n)))
+	for v := range t.priority {
+		t.priority[v] = rng.Int31()
+	}
+	for _, v := range order {
+		t.reset(v)
+		t.root = t.merge(t.root, v)
+	}
+	return t
+}
+
+func (t *OrderRing) reset(v int) {
+	t.left[v], t.right[v], t.parent[v], t.size[v] = nilNode, nilNode, nilNode, 1
+}
+
+func (t *OrderRing) Len() int { return t.sizeOf(t.root) }
+
+func (t *OrderRing) sizeOf(v int) int {
+	if v == nilNode {
+		return 0
+	}
+	return t.size[v]
+}
+
+func (t *OrderRing) update(v int) {
+	t.size[v] = 1 + t.sizeOf(t.left[v]) + t.sizeOf(t.right[v])
+	if t.left[v] != nilNode {
+		t.parent[t.left[v]] = v
+	}
+	if t.right[v] != nilNode {
+		t.parent[t.right[v]] = v
+	}
+}
+
+func (t *OrderRing) merge(a, b int) int {
+	switch {
+	case a == nilNode:
+		return b
+	case b == nilNode:
+		return a
+	case t.priority[a] > t.priority[b]:
+		t.right[a] = t.merge(t.right[a], b)
+		t.update(a)
+		t.parent[a] = nilNode
+		return a
+	default:
+		t.left[b] = t.merge(a, t.left[b])
+		t.update(b)
+		t.parent[b] = nilNode
+		return b
+	}
+}
+
+// split cuts the first k labels of v's subtree from the rest.
+func (t *OrderRing) split(v, k int) (int, int) {
+	if v == nilNode {
+		return nilNode, nilNode
+	}
+	t.parent[v] = nilNode
+	if t.sizeOf(t.left[v]) >= k {
+		l, r := t.split(t.left[v], k)
+		t.left[v] = r
+		t.update(v)
+		return l, v
+	}
+	l, r := t.split(t.right[v], k-t.sizeOf(t.left[v])-1)
+	t.right[v] = l
+	t.update(v)
+	return v, r
+}
+
+// Index returns v's position, counting from the start of the ring.
+func (t *OrderRing) Index(v int) int {
+	pos := t.sizeOf(t.left[v])
+	for cur, p := v, t.parent[v]; p != nilNode; cur, p = p, t.parent[p] {
+		if t.right[p] == cur {
+			pos += t.sizeOf(t.left[p]) + 1
+		}
+	}
+	return pos
+}
+
+// At returns the label at position i.
+func (t *OrderRing) At(i int) int {
+	v := t.root
+	for {
+		l := t.sizeOf(t.left[v])
+		switch {
+		case i < l:
+			v = t.left[v]
+		case i == l:
+			return v
+		default:
+			i -= l + 1
+			v = t.right[v]
+		}
+	}
+}
+
+func (t *OrderRing) Remove(v int) {
+	l, rest := t.split(t.root, t.Index(v))
+	_, r := t.split(rest, 1)
+	t.reset(v)
+	t.root = t.merge(l, r)
+}
+
+// InsertAt puts v, which must not be in the ring, at position i.
+func (t *OrderRing) InsertAt(i, v int) {
+	l, r := t.split(t.root, i)
+	t.reset(v)
+	t.root = t.merge(t.merge(l, v), r)
+}
+
+// Move shifts v k places around the ring past the other labels, backwards
+// for negative k.
+func (t *OrderRing) Move(v, k int) {
+	others := t.Len() - 1
+	if others == 0 {
+		return
+	}
+	i := t.Index(v)
+	t.Remove(v)
+	t.InsertAt(((i+k)%others+others)%others, v)
+}
+
+// Values lists the ring starting at label from.
+func (t *OrderRing) Values(from int) []int {
+	inorder := make([]int, 0, t.Len())
+	t.inorder(t.root, &inorder)
+
+	start := t.Index(from)
+	values := make([]int, 0, len(inorder))
+	values = append(values, inorder[start:]...)
+	return append(values, inorder[:start]...)
+}
+
+func (t *OrderRing) inorder(v int, values *[]int) {
+	if v == nilNode {
+		return
+	}
+	t.inorder(t.left[v], values)
+	*values = append(*values, v)
+	t.inorder(t.right[v], values)
+}
@@ -0,0 +1,49 @@
+package util
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// mixRing moves each label by its number, in label order, as 2022 day 20
+// mixes its file.
+func mixRing(nums []int, cycles int) *OrderRing {
+	order := make([]int, len(nums))
+	for i := range order {
+		order[i] = i
+	}
+	ring := NewOrderRing(order)
+	for c := 0; c < cycles; c++ {
+		for i, n := range nums {
+			ring.Move(i, n)
+		}
+	}
+	return ring
+}
+
+func TestOrderRingMix(t *testing.T) {
+	nums := []int{1, 2, -3, 3, -2, 0, 4}
+	ring := mixRing(nums, 1)
+	mixed := make([]int, 0, len(nums))
+	for _, i := range ring.Values(5) {
+		mixed = append(mixed, nums[i])
+	}
+	if want := []int{0, 3, -2, 1, 2, -3, 4}; !reflect.DeepEqual(mixed, want) {
+		t.Errorf("mixed to %v, want %v", mixed, want)
+	}
+}
+
+// BenchmarkOrderRingMix times a round of mixing a file the size of a real
+// input, with the decryption key applied.
+func BenchmarkOrderRingMix(b *testing.B) {
+	rng := rand.New(rand.NewSource(20))
+	nums := make([]int, 5000)
+	for i := range nums {
+		nums[i] = (rng.Intn(20001) - 10000) * 811589153
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mixRing(nums, 1)
+	}
+}
@@ -0,0 +1,161 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Packet is either an integer or a list of packets. The zero value is the
+// integer 0.
+type Packet struct {
+	IsList bool
+	Int    int
+	List   []Packet
+}
+
+func Int(n int) Packet { return Packet{Int: n} }
+
+func List(items ...Packet) Packet {
+	if items == nil {
+		items = []Packet{}
+	}
+	return Packet{IsList: true, List: items}
+}
+
+func Parse(s string) (Packet, error) {
+	var p Packet
+	err := json.Unmarshal([]byte(s), &p)
+	return p, err
+}
+
+func MustParse(s string) Packet {
+	p, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// UnmarshalJSON accepts only a list or an integer; null, like any other JSON
+// value, is an error rather than the zero Packet.
+func (p *Packet) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '[' {
+		items := make([]Packet, 0)
+		if err := json.Unmarshal(b, &items); err != nil {
+			return err
+		}
+		*p = List(items...)
+		return nil
+	}
+
+	// Unmarshal leaves n alone for null, so catch that first
+	var n int
+	if bytes.Equal(b, []byte("null")) {
+		return fmt.Errorf("packet %s: expected integer or list", b)
+	}
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("packet %s: expected integer or list", b)
+	}
+	*p = Int(n)
+	return nil
+}
+
+func (p Packet) MarshalJSON() ([]byte, error) {
+	if !p.IsList {
+		return json.Marshal(p.Int)
+	}
+	if p.List == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p.List)
+}
+
+func (p Packet) String() string {
+	if !p.IsList {
+		return strconv.Itoa(p.Int)
+	}
+	items := make([]string, len(p.List))
+	for i, item := range p.List {
+		items[i] = item.String()
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
+
+// Compare orders packets by the distress signal rules: integers numerically,
+// lists element by element then by length, and an integer against a list as
+// if it were a one-element list. Less wraps it for sort.Slice.
+func Compare(a, b Packet) int {
+	switch {
+	case !a.IsList && !b.IsList:
+		if a.Int < b.Int {
+			return -1
+		} else if a.Int > b.Int {
+			return 1
+		}
+		return 0
+	case !a.IsList:
+		return compareLists([]Packet{a}, b.List)
+	case !b.IsList:
+		return compareLists(a.List, []Packet{b})
+	default:
+		return compareLists(a.List, b.List)
+	}
+}
+
+func compareLists(a, b []Packet) int {
+	for i := range a {
+		if i >= len(b) {
+			return 1
+		}
+		if c := Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	if len(a) < len(b) {
+		return -1
+	}
+	return 0
+}
+
+func Less(a, b Packet) bool { return Compare(a, b) < 0 }
+
+// Equal reports whether a and b are structurally identical; 2 and [2]
+// compare as equal but are not Equal.
+func Equal(a, b Packet) bool {
+	if a.IsList != b.IsList {
+		return false
+	}
+	if !a.IsList {
+		return a.Int == b.Int
+	}
+	if len(a.List) != len(b.List) {
+		return false
+	}
+	for i := range a.List {
+		if !Equal(a.List[i], b.List[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Packets sorts with sort.Sort.
+type Packets []Packet
+
+func (ps Packets) Len() int           { return len(ps) }
+func (ps Packets) Less(i, j int) bool { return Less(ps[i], ps[j]) }
+func (ps Packets) Swap(i, j int)      { ps[i], ps[j] = ps[j], ps[i] }
+
+// Index returns the position of the first packet Equal to p, or -1.
+func (ps Packets) Index(p Packet) int {
+	for i, q := range ps {
+		if Equal(p, q) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,87 @@
+package packet
+
+import (
+	"bufio"
+	"os"
+	"testing"
+)
+
+func TestParseRejects(t *testing.T) {
+	for _, s := range []string{
+		"null", "[null]", "[1,[null]]", "true", "[false]", `"1"`, "[{}]", "1.5", "[1,2",
+	} {
+		if p, err := Parse(s); err == nil {
+			t.Errorf("Parse(%s) = %s, want error", s, p)
+		}
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	for _, s := range []string{"0", "[]", "[[]]", "[1,[2,[3,[4,[5,6,7]]]],8,9]", "-3"} {
+		p, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", s, err)
+		}
+		if p.String() != s {
+			t.Errorf("Parse(%s).String() = %s", s, p)
+		}
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// FuzzCompare checks that Compare is a strict weak ordering: irreflexive,
+// antisymmetric, and transitive in both order and equivalence.
+func FuzzCompare(f *testing.F) {
+	seeds := []string{"[]", "[[]]", "0", "[2]", "[[2]]", "[[6]]", "[1,[2,[3]]]"}
+	if file, err := os.Open("../../2022/day13/sample.txt"); err == nil {
+		sc := bufio.NewScanner(file)
+		for sc.Scan() {
+			if sc.Text() != "" {
+				seeds = append(seeds, sc.Text())
+			}
+		}
+		file.Close()
+	}
+	for i := range seeds {
+		f.Add(seeds[i], seeds[(i+1)%len(seeds)], seeds[(i+2)%len(seeds)])
+	}
+
+	f.Fuzz(func(t *testing.T, sa, sb, sc string) {
+		a, errA := Parse(sa)
+		b, errB := Parse(sb)
+		c, errC := Parse(sc)
+		if errA != nil || errB != nil || errC != nil {
+			t.Skip()
+		}
+
+		if Compare(a, a) != 0 {
+			t.Fatalf("Compare(%s, %s) != 0", a, a)
+		}
+		ab, bc, ac := sign(Compare(a, b)), sign(Compare(b, c)), sign(Compare(a, c))
+		if ba := sign(Compare(b, a)); ab != -ba {
+			t.Fatalf("Compare(%s, %s) = %d but Compare(%s, %s) = %d", a, b, ab, b, a, ba)
+		}
+		// a <= b <= c must give a <= c, strictly unless both steps are ties
+		if ab <= 0 && bc <= 0 {
+			if want := sign(ab + bc); ac != want {
+				t.Fatalf("Compare(%s, %s) = %d and Compare(%s, %s) = %d, but Compare(%s, %s) = %d",
+					a, b, ab, b, c, bc, a, c, ac)
+			}
+		}
+		if ab >= 0 && bc >= 0 {
+			if want := sign(ab + bc); ac != want {
+				t.Fatalf("Compare(%s, %s) = %d and Compare(%s, %s) = %d, but Compare(%s, %s) = %d",
+					a, b, ab, b, c, bc, a, c, ac)
+			}
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package pointcloud
+
+import (
+	"fmt"
+	"sort"
+)
+
+type Vec [3]int
+
+func (v Vec) Add(u Vec) Vec { return Vec{v[0] + u[0], v[1] + u[1], v[2] + u[2]} }
+func (v Vec) Sub(u Vec) Vec { return Vec{v[0] - u[0], v[1] - u[1], v[2] - u[2]} }
+
+func (v Vec) String() string { return fmt.Sprintf("%d,%d,%d", v[0], v[1], v[2]) }
+
+func (v Vec) Manhattan(u Vec) int {
+	d := v.Sub(u)
+	return abs(d[0]) + abs(d[1]) + abs(d[2])
+}
+
+// DistSq is the squared euclidean distance, which survives any rotation.
+func (v Vec) DistSq(u Vec) int {
+	d := v.Sub(u)
+	return d[0]*d[0] + d[1]*d[1] + d[2]*d[2]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func less(u, v Vec) bool {
+	for i := range u {
+		if u[i] != v[i] {
+			return u[i] < v[i]
+		}
+	}
+	return false
+}
+
+// Sort orders points by x, then y, then z.
+func Sort(points []Vec) {
+	sort.Slice(points, func(i, j int) bool { return less(points[i], points[j]) })
+}
+
+// Rotation is a 3x3 matrix, applied to column vectors.
+type Rotation [3][3]int
+
+var Identity = Rotation{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+func (r Rotation) Apply(v Vec) Vec {
+	var out Vec
+	for i := range r {
+		out[i] = r[i][0]*v[0] + r[i][1]*v[1] + r[i][2]*v[2]
+	}
+	return out
+}
+
+// Then is the rotation that applies r, then s.
+func (r Rotation) Then(s Rotation) Rotation {
+	var out Rotation
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				out[i][j] += s[i][k] * r[k][j]
+			}
+		}
+	}
+	return out
+}
+
+// Inverse of a rotation is its transpose.
+func (r Rotation) Inverse() Rotation {
+	var out Rotation
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			out[i][j] = r[j][i]
+		}
+	}
+	return out
+}
+
+func (r Rotation) det() int {
+	return r[0][0]*(r[1][1]*r[2][2]-r[1][2]*r[2][1]) -
+		r[0][1]*(r[1][0]*r[2][2]-r[1][2]*r[2][0]) +
+		r[0][2]*(r[1][0]*r[2][1]-r[1][1]*r[2][0])
+}
+
+func (r Rotation) String() string {
+	return fmt.Sprintf("[%v %v %v]", r[0], r[1], r[2])
+}
+
+// Rotations are the 24 ways to turn a cube: every signed permutation matrix
+// that keeps handedness.
+var Rotations = func() []Rotation {
+	perms := [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+	rotations := make([]Rotation, 0, 24)
+	for _, p := range perms {
+		for signs := 0; signs < 8; signs++ {
+			var r Rotation
+			for i := 0; i < 3; i++ {
+				r[i][p[i]] = 1 - 2*(signs>>i&1)
+			}
+			if r.det() == 1 {
+				rotations = append(rotations, r)
+			}
+		}
+	}
+	return rotations
+}()
+
+// Pose places a scanner's frame in a reference frame: a point v seen by the
+// scanner is at Rotation v + Translation, and the scanner itself at
+// Translation.
+type Pose struct {
+	Rotation    Rotation
+	Translation Vec
+}
+
+var Origin = Pose{Rotation: Identity}
+
+func (p Pose) Apply(v Vec) Vec {
+	return p.Rotation.Apply(v).Add(p.Translation)
+}
+
+// Then is the pose that applies p, then q.
+func (p Pose) Then(q Pose) Pose {
+	return Pose{p.Rotation.Then(q.Rotation), q.Apply(p.Translation)}
+}
+
+func (p Pose) String() string {
+	return fmt.Sprintf("rotation %v translation %v", p.Rotation, p.Translation)
+}
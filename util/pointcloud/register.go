@@ -0,0 +1,239 @@
+package pointcloud
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Cloud is the points one scanner sees, in its own frame.
+type Cloud []Vec
+
+// Parse reads scanner reports: a "--- scanner N ---" header, then one x,y,z
+// point per line, with reports separated by blank lines.
+func Parse(input []string) ([]Cloud, error) {
+	clouds := make([]Cloud, 0)
+	for i, line := range input {
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "---"):
+			clouds = append(clouds, make(Cloud, 0))
+		case len(clouds) == 0:
+			return nil, fmt.Errorf("pointcloud: line %d: point before any scanner header", i+1)
+		default:
+			var v Vec
+			if _, err := fmt.Sscanf(line, "%d,%d,%d", &v[0], &v[1], &v[2]); err != nil {
+				return nil, fmt.Errorf("pointcloud: line %d %q: %w", i+1, line, err)
+			}
+			clouds[len(clouds)-1] = append(clouds[len(clouds)-1], v)
+		}
+	}
+	return clouds, nil
+}
+
+// Fingerprint is the multiset of squared distances between every pair of a
+// cloud's points. Rotating or moving the cloud leaves it unchanged, so two
+// clouds sharing n points share at least n(n-1)/2 distances.
+type Fingerprint map[int]int
+
+func (c Cloud) Fingerprint() Fingerprint {
+	fp := make(Fingerprint)
+	for i := range c {
+		for j := i + 1; j < len(c); j++ {
+			fp[c[i].DistSq(c[j])]++
+		}
+	}
+	return fp
+}
+
+// Shared counts the distances two fingerprints have in common.
+func Shared(a, b Fingerprint) int {
+	shared := 0
+	for d, n := range a {
+		if m := b[d]; m < n {
+			shared += m
+		} else {
+			shared += n
+		}
+	}
+	return shared
+}
+
+type pair struct {
+	i, j int
+}
+
+func (c Cloud) pairs() map[int][]pair {
+	pairs := make(map[int][]pair)
+	for i := range c {
+		for j := i + 1; j < len(c); j++ {
+			d := c[i].DistSq(c[j])
+			pairs[d] = append(pairs[d], pair{i, j})
+		}
+	}
+	return pairs
+}
+
+func (c Cloud) set() map[Vec]bool {
+	set := make(map[Vec]bool, len(c))
+	for _, v := range c {
+		set[v] = true
+	}
+	return set
+}
+
+// overlap checks whether at least need of c's points, posed, land on ref.
+func overlap(ref map[Vec]bool, c Cloud, pose Pose, need int) bool {
+	matched := 0
+	for i, v := range c {
+		if ref[pose.Apply(v)] {
+			matched++
+			if matched >= need {
+				return true
+			}
+		} else if matched+len(c)-i-1 < need {
+			return false
+		}
+	}
+	return false
+}
+
+// Register finds the pose that lays at least minOverlap of c's points onto
+// ref's. Only pairs of points the same distance apart in both clouds are
+// tried against each other, and each such pairing fixes the rotation up to
+// the few that map one pair's offset onto the other's.
+func Register(ref, c Cloud, minOverlap int) (Pose, bool) {
+	refSet, refPairs := ref.set(), ref.pairs()
+	tried := make(map[Pose]bool)
+	for d, cPairs := range c.pairs() {
+		for _, rp := range refPairs[d] {
+			refDelta := ref[rp.j].Sub(ref[rp.i])
+			for _, cp := range cPairs {
+				cDelta := c[cp.j].Sub(c[cp.i])
+				for _, r := range Rotations {
+					var pose Pose
+					switch r.Apply(cDelta) {
+					case refDelta:
+						pose = Pose{r, ref[rp.i].Sub(r.Apply(c[cp.i]))}
+					case Vec{}.Sub(refDelta):
+						pose = Pose{r, ref[rp.j].Sub(r.Apply(c[cp.i]))}
+					default:
+						continue
+					}
+
+					if tried[pose] {
+						continue
+					}
+					tried[pose] = true
+					if overlap(refSet, c, pose, minOverlap) {
+						return pose, true
+					}
+				}
+			}
+		}
+	}
+	return Pose{}, false
+}
+
+// RegisterExhaustive tries every rotation with every pairing of a point in
+// ref and a point in c. It is the slow baseline Register is measured against.
+func RegisterExhaustive(ref, c Cloud, minOverlap int) (Pose, bool) {
+	refSet := ref.set()
+	for _, r := range Rotations {
+		for _, a := range ref {
+			for _, b := range c {
+				pose := Pose{r, a.Sub(r.Apply(b))}
+				if overlap(refSet, c, pose, minOverlap) {
+					return pose, true
+				}
+			}
+		}
+	}
+	return Pose{}, false
+}
+
+// Survey is every scanner placed in the frame of the first, and the beacons
+// they saw between them.
+type Survey struct {
+	Poses   []Pose
+	Beacons []Vec // sorted, without duplicates
+}
+
+// Solve registers each cloud against those already placed, starting from the
+// first, only trying pairs whose fingerprints share enough distances.
+func Solve(clouds []Cloud, minOverlap int) (*Survey, error) {
+	fps := make([]Fingerprint, len(clouds))
+	for i, c := range clouds {
+		fps[i] = c.Fingerprint()
+	}
+	shared := minOverlap * (minOverlap - 1) / 2
+	candidate := func(i, j int) bool { return Shared(fps[i], fps[j]) >= shared }
+	return solve(clouds, minOverlap, candidate, Register)
+}
+
+// SolveExhaustive places clouds by trying every pair with
+// RegisterExhaustive, as a baseline for Solve.
+func SolveExhaustive(clouds []Cloud, minOverlap int) (*Survey, error) {
+	return solve(clouds, minOverlap, func(i, j int) bool { return true }, RegisterExhaustive)
+}
+
+func solve(clouds []Cloud, minOverlap int, candidate func(i, j int) bool,
+	register func(ref, c Cloud, minOverlap int) (Pose, bool)) (*Survey, error) {
+	if len(clouds) == 0 {
+		return &Survey{}, nil
+	}
+
+	poses := make([]Pose, len(clouds))
+	placed := make([]bool, len(clouds))
+	poses[0], placed[0] = Origin, true
+	queue := []int{0}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for j := range clouds {
+			if placed[j] || !candidate(i, j) {
+				continue
+			}
+			if pose, ok := register(clouds[i], clouds[j], minOverlap); ok {
+				poses[j], placed[j] = pose.Then(poses[i]), true
+				queue = append(queue, j)
+			}
+		}
+	}
+
+	unplaced := make([]int, 0)
+	for j, ok := range placed {
+		if !ok {
+			unplaced = append(unplaced, j)
+		}
+	}
+	if len(unplaced) > 0 {
+		return nil, fmt.Errorf("pointcloud: scanners %v overlap no placed scanner", unplaced)
+	}
+
+	seen := make(map[Vec]bool)
+	beacons := make([]Vec, 0)
+	for i, c := range clouds {
+		for _, v := range c {
+			if v = poses[i].Apply(v); !seen[v] {
+				seen[v] = true
+				beacons = append(beacons, v)
+			}
+		}
+	}
+	Sort(beacons)
+	return &Survey{Poses: poses, Beacons: beacons}, nil
+}
+
+// Spread is the largest Manhattan distance between two scanners.
+func (s *Survey) Spread() int {
+	spread := 0
+	for _, p := range s.Poses {
+		for _, q := range s.Poses {
+			if d := p.Translation.Manhattan(q.Translation); d > spread {
+				spread = d
+			}
+		}
+	}
+	return spread
+}
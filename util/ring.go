@@ -0,0 +1,91 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ring is a circular doubly linked list over the labels 0..size-1, stored as
+// next/prev arrays so every label is its own handle. Labels not in the ring
+// point at themselves.
+type Ring struct {
+	next, prev []int
+	len        int
+}
+
+// NewRing links order into a ring, first to last and back to first.
+func NewRing(size int, order []int) *Ring {
+	r := &Ring{next: make([]int, size), prev: make([]int, size)}
+	for i := range r.next {
+		r.next[i], r.prev[i] = i, i
+	}
+	for i, v := range order {
+		if i > 0 {
+			r.InsertAfter(order[i-1], v)
+		} else {
+			r.len = 1
+		}
+	}
+	return r
+}
+
+func (r *Ring) Len() int       { return r.len }
+func (r *Ring) Next(v int) int { return r.next[v] }
+func (r *Ring) Prev(v int) int { return r.prev[v] }
+
+// InsertAfter puts v, which must not be in the ring, right after at.
+func (r *Ring) InsertAfter(at, v int) {
+	after := r.next[at]
+	r.next[at], r.prev[v] = v, at
+	r.next[v], r.prev[after] = after, v
+	r.len++
+}
+
+func (r *Ring) Remove(v int) {
+	r.next[r.prev[v]], r.prev[r.next[v]] = r.next[v], r.prev[v]
+	r.next[v], r.prev[v] = v, v
+	r.len--
+}
+
+// Splice moves the run first..last, taken in ring order, to just after at.
+// at must not be inside the run.
+func (r *Ring) Splice(first, last, at int) {
+	before, after := r.prev[first], r.next[last]
+	r.next[before], r.prev[after] = after, before
+
+	dest := r.next[at]
+	r.next[at], r.prev[first] = first, at
+	r.next[last], r.prev[dest] = dest, last
+}
+
+// Walk returns the label n steps clockwise from v, or counterclockwise for
+// negative n.
+func (r *Ring) Walk(v, n int) int {
+	for ; n > 0; n-- {
+		v = r.next[v]
+	}
+	for ; n < 0; n++ {
+		v = r.prev[v]
+	}
+	return v
+}
+
+// Values lists the ring clockwise starting at from.
+func (r *Ring) Values(from int) []int {
+	values := make([]int, 0, r.len)
+	v := from
+	for i := 0; i < r.len; i++ {
+		values = append(values, v)
+		v = r.next[v]
+	}
+	return values
+}
+
+func (r *Ring) Format(from int) string {
+	values := r.Values(from)
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = fmt.Sprint(v)
+	}
+	return strings.Join(strs, " ")
+}
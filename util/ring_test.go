@@ -0,0 +1,53 @@
+package util
+
+import "testing"
+
+// cups plays the crab's game from 2020 day 23 on a ring: each move takes the
+// three cups after the current one and puts them after the next lower label.
+type cups struct {
+	ring    *Ring
+	current int
+	max     int
+}
+
+func newCups(labels []int, max int) *cups {
+	order := append([]int(nil), labels...)
+	for v := len(labels) + 1; v <= max; v++ {
+		order = append(order, v)
+	}
+	return &cups{NewRing(max+1, order), order[0], max}
+}
+
+func (c *cups) move() {
+	first := c.ring.Next(c.current)
+	second := c.ring.Next(first)
+	last := c.ring.Next(second)
+
+	dest := c.current
+	for dest == c.current || dest == first || dest == second || dest == last {
+		if dest--; dest < 1 {
+			dest = c.max
+		}
+	}
+	c.ring.Splice(first, last, dest)
+	c.current = c.ring.Next(c.current)
+}
+
+func TestRingCups(t *testing.T) {
+	c := newCups([]int{3, 8, 9, 1, 2, 5, 4, 6, 7}, 9)
+	for i := 0; i < 10; i++ {
+		c.move()
+	}
+	if got, want := c.ring.Format(1), "1 9 2 6 5 8 3 7 4"; got != want {
+		t.Errorf("after 10 moves got %s, want %s", got, want)
+	}
+}
+
+// BenchmarkRingCups times single moves in the million-cup game of part 2.
+func BenchmarkRingCups(b *testing.B) {
+	c := newCups([]int{3, 8, 9, 1, 2, 5, 4, 6, 7}, 1000000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.move()
+	}
+}
@@ -0,0 +1,96 @@
+package sand
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// bounds is the columns holding any rock, sand or source, and the rows down
+// to the lowest rock, or the floor.
+func (c *Cave) bounds() (minX, maxX, maxY int) {
+	minX, maxX = c.Sources[0].X, c.Sources[0].X
+	for _, s := range c.Sources {
+		if s.X < minX {
+			minX = s.X
+		}
+		if s.X > maxX {
+			maxX = s.X
+		}
+	}
+
+	// The floor spans the whole cave, so leave it out when finding the sides
+	for y := 0; y < c.floor; y++ {
+		for x := c.minX; x < c.minX+c.width; x++ {
+			if c.At(Point{x, y}) != air {
+				if x < minX {
+					minX = x
+				}
+				if x > maxX {
+					maxX = x
+				}
+			}
+		}
+	}
+	if c.Mode == Floor {
+		return minX, maxX, c.floor
+	}
+	return minX, maxX, c.floor - 2
+}
+
+func (c *Cave) cell(p Point) byte {
+	b := c.At(p)
+	if b == air {
+		for _, s := range c.Sources {
+			if p == s {
+				return '+'
+			}
+		}
+	}
+	return b
+}
+
+// String draws the cave the way the puzzle does, trimmed to what is in it.
+func (c *Cave) String() string {
+	minX, maxX, maxY := c.bounds()
+	var b strings.Builder
+	for y := 0; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			b.WriteByte(c.cell(Point{x, y}))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// shades of grey for each cell in a PGM image
+var shades = map[byte]byte{air: 255, rock: 64, sand: 192, '+': 0}
+
+// WritePGM writes the same view as String as a binary PGM image, each cell
+// scale pixels square.
+func (c *Cave) WritePGM(w io.Writer, scale int) error {
+	if scale < 1 {
+		scale = 1
+	}
+	minX, maxX, maxY := c.bounds()
+	width, height := (maxX-minX+1)*scale, (maxY+1)*scale
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P5\n%d %d\n255\n", width, height)
+	row := make([]byte, width)
+	for y := 0; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			shade := shades[c.cell(Point{x, y})]
+			for i := 0; i < scale; i++ {
+				row[(x-minX)*scale+i] = shade
+			}
+		}
+		for i := 0; i < scale; i++ {
+			if _, err := bw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,197 @@
+package sand
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Point struct {
+	X, Y int
+}
+
+// ParsePaths reads rock paths, one per line: x,y points joined by " -> ",
+// each pair joined by a straight line of rock.
+func ParsePaths(input []string) ([][]Point, error) {
+	paths := make([][]Point, 0, len(input))
+	for i, line := range input {
+		if line == "" {
+			continue
+		}
+		path := make([]Point, 0)
+		for _, s := range strings.Split(line, " -> ") {
+			var p Point
+			if _, err := fmt.Sscanf(s, "%d,%d", &p.X, &p.Y); err != nil {
+				return nil, fmt.Errorf("sand: line %d: bad point %q: %w", i+1, s, err)
+			}
+			if n := len(path); n > 0 && path[n-1].X != p.X && path[n-1].Y != p.Y {
+				return nil, fmt.Errorf("sand: line %d: %q is not straight on from the point before", i+1, s)
+			}
+			path = append(path, p)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Mode is what lies below the lowest rock.
+type Mode int
+
+const (
+	// Abyss swallows sand for ever, and pouring stops at the first grain lost.
+	Abyss Mode = iota
+	// Floor is endless rock two below the lowest rock, and pouring stops once
+	// sand blocks every source.
+	Floor
+)
+
+const (
+	air  = '.'
+	rock = '#'
+	sand = 'o'
+)
+
+// Cave is a slice of rock, sand and air wide enough for any sand that can
+// settle. Y grows downwards.
+type Cave struct {
+	Mode    Mode
+	Sources []Point
+	Grains  int  // settled so far
+	Lost    bool // whether a grain has fallen into the abyss
+
+	minX, width, height int
+	cells               []byte
+	floor               int // y of the floor, or of the abyss's edge
+}
+
+// New lays out paths of rock, with sand to pour from sources.
+func New(paths [][]Point, sources []Point, mode Mode) (*Cave, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("sand: no sources")
+	}
+	minX, maxX, maxY := sources[0].X, sources[0].X, 0
+	for _, path := range paths {
+		for _, p := range path {
+			if p.Y < 0 {
+				return nil, fmt.Errorf("sand: rock at %d,%d is above the top", p.X, p.Y)
+			}
+			if p.X < minX {
+				minX = p.X
+			}
+			if p.X > maxX {
+				maxX = p.X
+			}
+			if p.Y > maxY {
+				maxY = p.Y
+			}
+		}
+	}
+
+	c := &Cave{Mode: mode, Sources: sources, floor: maxY + 2}
+	for _, s := range sources {
+		if s.Y < 0 || s.Y >= c.floor {
+			return nil, fmt.Errorf("sand: source %d,%d is not above the lowest rock", s.X, s.Y)
+		}
+		// Sand piles into a triangle at worst, one wider each row down
+		if mode == Floor {
+			if x := s.X - (c.floor - s.Y); x < minX {
+				minX = x
+			}
+			if x := s.X + (c.floor - s.Y); x > maxX {
+				maxX = x
+			}
+		}
+	}
+
+	c.minX, c.width, c.height = minX-1, maxX-minX+3, c.floor+1
+	c.cells = []byte(strings.Repeat(string(air), c.width*c.height))
+	for _, path := range paths {
+		for i := 1; i < len(path); i++ {
+			c.line(path[i-1], path[i])
+		}
+		if len(path) == 1 {
+			c.set(path[0], rock)
+		}
+	}
+	if mode == Floor {
+		for x := 0; x < c.width; x++ {
+			c.cells[c.floor*c.width+x] = rock
+		}
+	}
+	return c, nil
+}
+
+func (c *Cave) line(a, b Point) {
+	dx, dy := sign(b.X-a.X), sign(b.Y-a.Y)
+	for p := a; ; p = (Point{p.X + dx, p.Y + dy}) {
+		c.set(p, rock)
+		if p == b {
+			break
+		}
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func (c *Cave) inside(p Point) bool {
+	return p.X >= c.minX && p.X < c.minX+c.width && p.Y >= 0 && p.Y < c.height
+}
+
+func (c *Cave) At(p Point) byte {
+	if !c.inside(p) {
+		return air
+	}
+	return c.cells[p.Y*c.width+p.X-c.minX]
+}
+
+func (c *Cave) set(p Point, b byte) { c.cells[p.Y*c.width+p.X-c.minX] = b }
+
+// Pour drops sand from each source in turn until it stops, calling watch, if
+// not nil, after each grain settles. Rather than start every grain from the
+// source, it keeps the path the last grain fell along: the next grain falls
+// the same way until the cell the last one settled in, so it carries on from
+// the cell before that. It returns the number of grains settled.
+func (c *Cave) Pour(watch func(grains int)) int {
+	for _, s := range c.Sources {
+		path := []Point{s}
+		for len(path) > 0 && !c.Lost {
+			p := path[len(path)-1]
+			if c.At(p) != air {
+				path = path[:len(path)-1]
+				continue
+			}
+
+			moved := false
+			for _, dx := range [3]int{0, -1, 1} {
+				q := Point{p.X + dx, p.Y + 1}
+				if c.Mode == Abyss && (q.Y >= c.floor || !c.inside(q)) {
+					c.Lost = true
+					break
+				}
+				if c.At(q) == air {
+					path = append(path, q)
+					moved = true
+					break
+				}
+			}
+			if moved || c.Lost {
+				continue
+			}
+
+			c.set(p, sand)
+			c.Grains++
+			path = path[:len(path)-1]
+			if watch != nil {
+				watch(c.Grains)
+			}
+		}
+	}
+	return c.Grains
+}
@@ -0,0 +1,78 @@
+package sand
+
+import "testing"
+
+var sample = []string{
+	"498,4 -> 498,6 -> 496,6",
+	"503,4 -> 502,4 -> 502,9 -> 494,9",
+}
+
+func pour(t *testing.T, input []string, sources []Point, mode Mode) *Cave {
+	t.Helper()
+	paths, err := ParsePaths(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(paths, sources, mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Pour(nil)
+	return c
+}
+
+func TestSample(t *testing.T) {
+	source := []Point{{X: 500, Y: 0}}
+	if c := pour(t, sample, source, Abyss); c.Grains != 24 || !c.Lost {
+		t.Errorf("abyss: %d grains, lost %t; want 24, true", c.Grains, c.Lost)
+	}
+	if c := pour(t, sample, source, Floor); c.Grains != 93 || c.Lost {
+		t.Errorf("floor: %d grains, lost %t; want 93, false", c.Grains, c.Lost)
+	}
+}
+
+// filled counts the cells sand can reach falling from sources onto the
+// floor; with a floor every one of them ends up full.
+func filled(c *Cave) int {
+	seen := make(map[Point]bool)
+	queue := append([]Point(nil), c.Sources...)
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if seen[p] || p.Y >= c.floor || c.At(p) == rock {
+			continue
+		}
+		seen[p] = true
+		for dx := -1; dx <= 1; dx++ {
+			queue = append(queue, Point{p.X + dx, p.Y + 1})
+		}
+	}
+	return len(seen)
+}
+
+func TestFloorSources(t *testing.T) {
+	// Two touching piles on an empty floor two below the sources
+	c := pour(t, nil, []Point{{X: 500, Y: 0}, {X: 501, Y: 0}}, Floor)
+	if c.Grains != 6 {
+		t.Errorf("empty cave: %d grains, want 6", c.Grains)
+	}
+
+	paths, err := ParsePaths(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := []Point{{X: 500, Y: 0}, {X: 490, Y: 3}}
+	c, err = New(paths, sources, Floor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filled(c)
+	if c.Pour(nil); c.Grains != want {
+		t.Errorf("sample from %v: %d grains, want %d", sources, c.Grains, want)
+	}
+	for _, s := range sources {
+		if c.At(s) != sand {
+			t.Errorf("source %v is not blocked", s)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package snailfish
+
+import "fmt"
+
+type SyntaxError struct {
+	Input  string
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("snailfish: %s at offset %d of %q", e.Msg, e.Offset, e.Input)
+}
+
+func Parse(s string) (Number, error) {
+	p := parser{input: s}
+	if err := p.element(0); err != nil {
+		return nil, err
+	}
+	if p.pos != len(s) {
+		return nil, p.errorf("unexpected %q after number", s[p.pos])
+	}
+	return p.leaves, nil
+}
+
+func ParseAll(input []string) ([]Number, error) {
+	nums := make([]Number, len(input))
+	for i, line := range input {
+		n, err := Parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+type parser struct {
+	input  string
+	pos    int
+	leaves Number
+}
+
+func (p *parser) errorf(format string, a ...interface{}) error {
+	return &SyntaxError{p.input, p.pos, fmt.Sprintf(format, a...)}
+}
+
+func (p *parser) expect(b byte) error {
+	if p.pos >= len(p.input) {
+		return p.errorf("expected %q, found end of input", b)
+	}
+	if p.input[p.pos] != b {
+		return p.errorf("expected %q, found %q", b, p.input[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) element(depth int) error {
+	if p.pos >= len(p.input) {
+		return p.errorf("unexpected end of input")
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c == '[':
+		p.pos++
+		if err := p.element(depth + 1); err != nil {
+			return err
+		}
+		if err := p.expect(','); err != nil {
+			return err
+		}
+		if err := p.element(depth + 1); err != nil {
+			return err
+		}
+		return p.expect(']')
+	case c >= '0' && c <= '9':
+		value := 0
+		for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			value = value*10 + int(p.input[p.pos]-'0')
+			p.pos++
+		}
+		p.leaves = append(p.leaves, Leaf{value, depth})
+		return nil
+	default:
+		return p.errorf("unexpected %q", c)
+	}
+}
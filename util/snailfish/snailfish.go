@@ -0,0 +1,189 @@
+package snailfish
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Leaf is a regular number together with the count of pairs enclosing it.
+type Leaf struct {
+	Value, Depth int
+}
+
+// Number stores a snailfish number as its leaves in left-to-right order. The
+// depths alone are enough to recover the pair structure.
+type Number []Leaf
+
+func (n Number) Clone() Number {
+	return append(Number(nil), n...)
+}
+
+func (n Number) String() string {
+	if len(n) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	n.write(&b, 0, 0)
+	return b.String()
+}
+
+func (n Number) write(b *strings.Builder, i, depth int) int {
+	if n[i].Depth == depth {
+		b.WriteString(strconv.Itoa(n[i].Value))
+		return i + 1
+	}
+
+	b.WriteByte('[')
+	i = n.write(b, i, depth+1)
+	b.WriteByte(',')
+	i = n.write(b, i, depth+1)
+	b.WriteByte(']')
+	return i
+}
+
+// Magnitude folds pairs from the innermost out, three times the left plus
+// twice the right. The empty Number, as Sum returns for no numbers, has
+// magnitude 0.
+func (n Number) Magnitude() int {
+	if len(n) == 0 {
+		return 0
+	}
+	stack := make([]Leaf, 0, len(n))
+	for _, leaf := range n {
+		stack = append(stack, leaf)
+		for len(stack) >= 2 {
+			l, r := stack[len(stack)-2], stack[len(stack)-1]
+			if l.Depth != r.Depth {
+				break
+			}
+			stack = stack[:len(stack)-2]
+			stack = append(stack, Leaf{3*l.Value + 2*r.Value, l.Depth - 1})
+		}
+	}
+	return stack[0].Value
+}
+
+type EventKind int
+
+const (
+	Explode EventKind = iota
+	Split
+)
+
+func (k EventKind) String() string {
+	if k == Explode {
+		return "explode"
+	}
+	return "split"
+}
+
+// Event records one reduction step. Leaf is the index of the affected leaf
+// before the step; Pair holds the exploded pair, or the two halves of a split.
+type Event struct {
+	Kind   EventKind
+	Leaf   int
+	Pair   [2]int
+	Result Number
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("after %-7s %s", e.Kind.String()+":", e.Result)
+}
+
+func join(a, b Number) Number {
+	n := make(Number, 0, len(a)+len(b))
+	for _, leaf := range a {
+		n = append(n, Leaf{leaf.Value, leaf.Depth + 1})
+	}
+	for _, leaf := range b {
+		n = append(n, Leaf{leaf.Value, leaf.Depth + 1})
+	}
+	return n
+}
+
+func Add(a, b Number) Number {
+	n := join(a, b)
+	for n.step(nil) {
+	}
+	return n
+}
+
+// AddTrace is Add, also returning every intermediate reduction step.
+func AddTrace(a, b Number) (Number, []Event) {
+	n := join(a, b)
+	events := make([]Event, 0)
+	for {
+		var e Event
+		if !n.step(&e) {
+			return n, events
+		}
+		e.Result = n.Clone()
+		events = append(events, e)
+	}
+}
+
+func Sum(nums []Number) Number {
+	if len(nums) == 0 {
+		return nil
+	}
+	sum := nums[0]
+	for _, n := range nums[1:] {
+		sum = Add(sum, n)
+	}
+	return sum
+}
+
+// step applies the first applicable reduction in place, optionally recording
+// it in e, and reports whether anything changed.
+func (n *Number) step(e *Event) bool {
+	return n.explode(e) || n.split(e)
+}
+
+func (n *Number) explode(e *Event) bool {
+	leaves := *n
+	for i := 0; i+1 < len(leaves); i++ {
+		if leaves[i].Depth <= 4 || leaves[i].Depth != leaves[i+1].Depth {
+			continue
+		}
+
+		l, r := leaves[i].Value, leaves[i+1].Value
+		if i > 0 {
+			leaves[i-1].Value += l
+		}
+		if i+2 < len(leaves) {
+			leaves[i+2].Value += r
+		}
+
+		leaves[i] = Leaf{0, leaves[i].Depth - 1}
+		*n = append(leaves[:i+1], leaves[i+2:]...)
+
+		if e != nil {
+			*e = Event{Kind: Explode, Leaf: i, Pair: [2]int{l, r}}
+		}
+		return true
+	}
+	return false
+}
+
+func (n *Number) split(e *Event) bool {
+	leaves := *n
+	for i, leaf := range leaves {
+		if leaf.Value < 10 {
+			continue
+		}
+
+		l, r := leaf.Value/2, leaf.Value-leaf.Value/2
+		leaves = append(leaves, Leaf{})
+		copy(leaves[i+1:], leaves[i:])
+		leaves[i] = Leaf{l, leaf.Depth + 1}
+		leaves[i+1] = Leaf{r, leaf.Depth + 1}
+		*n = leaves
+
+		if e != nil {
+			*e = Event{Kind: Split, Leaf: i, Pair: [2]int{l, r}}
+		}
+		return true
+	}
+	return false
+}
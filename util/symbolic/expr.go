@@ -0,0 +1,109 @@
+package symbolic
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Expr is a binary expression tree over rational constants and named
+// variables. Leaves have Op == 0 and either a Name or a Value.
+type Expr struct {
+	Op          byte
+	Left, Right *Expr
+	Name        string
+	Value       *big.Rat
+}
+
+func Const(v *big.Rat) *Expr { return &Expr{Value: v} }
+func Int(n int64) *Expr      { return Const(big.NewRat(n, 1)) }
+func Var(name string) *Expr  { return &Expr{Name: name} }
+
+func Binary(op byte, left, right *Expr) *Expr {
+	return &Expr{Op: op, Left: left, Right: right}
+}
+
+func (e *Expr) IsConst() bool { return e.Op == 0 && e.Value != nil }
+func (e *Expr) IsVar() bool   { return e.Op == 0 && e.Value == nil }
+
+func (e *Expr) String() string {
+	switch {
+	case e.IsConst():
+		return e.Value.RatString()
+	case e.IsVar():
+		return e.Name
+	default:
+		return fmt.Sprintf("(%s %c %s)", e.Left, e.Op, e.Right)
+	}
+}
+
+// Occurrences counts how many times each variable appears in the tree.
+func (e *Expr) Occurrences() map[string]int {
+	counts := make(map[string]int)
+	e.countVars(counts)
+	return counts
+}
+
+func (e *Expr) countVars(counts map[string]int) {
+	if e.IsVar() {
+		counts[e.Name]++
+	} else if e.Op != 0 {
+		e.Left.countVars(counts)
+		e.Right.countVars(counts)
+	}
+}
+
+// Simplify folds every variable-free subtree into a constant.
+func (e *Expr) Simplify() (*Expr, error) {
+	if e.Op == 0 {
+		return e, nil
+	}
+
+	left, err := e.Left.Simplify()
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.Right.Simplify()
+	if err != nil {
+		return nil, err
+	}
+
+	if left.IsConst() && right.IsConst() {
+		v, err := apply(e.Op, left.Value, right.Value)
+		if err != nil {
+			return nil, err
+		}
+		return Const(v), nil
+	}
+	return Binary(e.Op, left, right), nil
+}
+
+// Eval evaluates an expression with no free variables.
+func (e *Expr) Eval() (*big.Rat, error) {
+	s, err := e.Simplify()
+	if err != nil {
+		return nil, err
+	}
+	if !s.IsConst() {
+		return nil, fmt.Errorf("expression %s has free variables", s)
+	}
+	return s.Value, nil
+}
+
+func apply(op byte, a, b *big.Rat) (*big.Rat, error) {
+	r := new(big.Rat)
+	switch op {
+	case '+':
+		return r.Add(a, b), nil
+	case '-':
+		return r.Sub(a, b), nil
+	case '*':
+		return r.Mul(a, b), nil
+	case '/':
+		if b.Sign() == 0 {
+			return nil, fmt.Errorf("division by zero: %s / 0", a.RatString())
+		}
+		return r.Quo(a, b), nil
+	default:
+		return nil, fmt.Errorf("unknown operator %q", op)
+	}
+}
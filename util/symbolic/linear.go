@@ -0,0 +1,186 @@
+package symbolic
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+var ErrNonLinear = errors.New("expression is not linear")
+
+// Linear is sum(Coef[v] * v) + Const.
+type Linear struct {
+	Coef  map[string]*big.Rat
+	Const *big.Rat
+}
+
+func newLinear() *Linear {
+	return &Linear{make(map[string]*big.Rat), new(big.Rat)}
+}
+
+func (l *Linear) IsConst() bool { return len(l.Coef) == 0 }
+
+func (l *Linear) Vars() []string {
+	vars := make([]string, 0, len(l.Coef))
+	for v := range l.Coef {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+func (l *Linear) String() string {
+	terms := make([]string, 0, len(l.Coef)+1)
+	for _, v := range l.Vars() {
+		terms = append(terms, l.Coef[v].RatString()+"*"+v)
+	}
+	if l.Const.Sign() != 0 || len(terms) == 0 {
+		terms = append(terms, l.Const.RatString())
+	}
+	return strings.Join(terms, " + ")
+}
+
+// combine returns a + sign*b.
+func combine(a, b *Linear, sign int) *Linear {
+	l := newLinear()
+	for v, c := range a.Coef {
+		l.Coef[v] = new(big.Rat).Set(c)
+	}
+	for v, c := range b.Coef {
+		term := new(big.Rat).Mul(c, big.NewRat(int64(sign), 1))
+		if prev, found := l.Coef[v]; found {
+			term.Add(term, prev)
+		}
+		if term.Sign() == 0 {
+			delete(l.Coef, v)
+		} else {
+			l.Coef[v] = term
+		}
+	}
+	l.Const.Add(a.Const, new(big.Rat).Mul(b.Const, big.NewRat(int64(sign), 1)))
+	return l
+}
+
+func (l *Linear) scale(k *big.Rat) *Linear {
+	scaled := newLinear()
+	if k.Sign() == 0 {
+		return scaled
+	}
+	for v, c := range l.Coef {
+		scaled.Coef[v] = new(big.Rat).Mul(c, k)
+	}
+	scaled.Const.Mul(l.Const, k)
+	return scaled
+}
+
+// Linearize reduces e to linear form, failing with ErrNonLinear when two
+// variable terms are multiplied or a variable term ends up in a divisor.
+func (e *Expr) Linearize() (*Linear, error) {
+	switch {
+	case e.IsConst():
+		l := newLinear()
+		l.Const.Set(e.Value)
+		return l, nil
+	case e.IsVar():
+		l := newLinear()
+		l.Coef[e.Name] = big.NewRat(1, 1)
+		return l, nil
+	}
+
+	left, err := e.Left.Linearize()
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.Right.Linearize()
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.Op {
+	case '+':
+		return combine(left, right, 1), nil
+	case '-':
+		return combine(left, right, -1), nil
+	case '*':
+		if left.IsConst() {
+			return right.scale(left.Const), nil
+		}
+		if right.IsConst() {
+			return left.scale(right.Const), nil
+		}
+		return nil, fmt.Errorf("%w: %s multiplies two variable terms", ErrNonLinear, e)
+	case '/':
+		if !right.IsConst() {
+			return nil, fmt.Errorf("%w: %s divides by a variable term", ErrNonLinear, e)
+		}
+		if right.Const.Sign() == 0 {
+			return nil, fmt.Errorf("division by zero in %s", e)
+		}
+		return left.scale(new(big.Rat).Inv(right.Const)), nil
+	default:
+		return nil, fmt.Errorf("unknown operator %q", e.Op)
+	}
+}
+
+type Equation struct {
+	Left, Right *Expr
+}
+
+func (eq Equation) String() string {
+	return fmt.Sprintf("%s = %s", eq.Left, eq.Right)
+}
+
+// Simplify folds constants on both sides.
+func (eq Equation) Simplify() (Equation, error) {
+	left, err := eq.Left.Simplify()
+	if err != nil {
+		return Equation{}, err
+	}
+	right, err := eq.Right.Simplify()
+	if err != nil {
+		return Equation{}, err
+	}
+	return Equation{left, right}, nil
+}
+
+// Linear returns left - right in linear form, so the equation reads l = 0.
+func (eq Equation) Linear() (*Linear, error) {
+	left, err := eq.Left.Linearize()
+	if err != nil {
+		return nil, err
+	}
+	right, err := eq.Right.Linearize()
+	if err != nil {
+		return nil, err
+	}
+	return combine(left, right, -1), nil
+}
+
+// Solve finds the value of name. All other variables must cancel out, and
+// name must not cancel out itself.
+func (eq Equation) Solve(name string) (*big.Rat, error) {
+	l, err := eq.Linear()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range l.Vars() {
+		if v != name {
+			return nil, fmt.Errorf("solution for %s depends on %s", name, v)
+		}
+	}
+
+	coef, found := l.Coef[name]
+	if !found {
+		if l.Const.Sign() == 0 {
+			return nil, fmt.Errorf("%s is unconstrained: equation always holds", name)
+		}
+		return nil, fmt.Errorf("no solution for %s: equation never holds", name)
+	}
+
+	// coef*name + const = 0
+	solution := new(big.Rat).Neg(l.Const)
+	return solution.Quo(solution, coef), nil
+}
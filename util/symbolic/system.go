@@ -0,0 +1,116 @@
+package symbolic
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+type definition struct {
+	value       *big.Rat
+	op          byte
+	left, right string
+}
+
+// System is a set of named definitions such as "root: pppw + sjmn" or
+// "dbpl: 5", which may reference each other in any order.
+type System struct {
+	defs map[string]definition
+	free map[string]bool
+}
+
+func ParseSystem(input []string) (*System, error) {
+	s := &System{make(map[string]definition), make(map[string]bool)}
+	for _, line := range input {
+		name, body, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("definition %q: missing ':'", line)
+		}
+
+		fields := strings.Fields(body)
+		switch len(fields) {
+		case 1:
+			v, ok := new(big.Rat).SetString(fields[0])
+			if !ok {
+				return nil, fmt.Errorf("definition %q: bad number", line)
+			}
+			s.defs[name] = definition{value: v}
+		case 3:
+			if len(fields[1]) != 1 || !strings.Contains("+-*/", fields[1]) {
+				return nil, fmt.Errorf("definition %q: bad operator", line)
+			}
+			s.defs[name] = definition{op: fields[1][0], left: fields[0], right: fields[2]}
+		default:
+			return nil, fmt.Errorf("definition %q: expected value or binary operation", line)
+		}
+	}
+	return s, nil
+}
+
+// Free makes name an unknown, ignoring any definition it has.
+func (s *System) Free(name string) {
+	s.free[name] = true
+}
+
+// Expr expands name into an expression tree. Shared definitions become shared
+// subtrees.
+func (s *System) Expr(name string) (*Expr, error) {
+	return s.expand(name, make(map[string]*Expr), make(map[string]bool))
+}
+
+// Equation splits name's operation into an equation between its operands.
+func (s *System) Equation(name string) (Equation, error) {
+	d, found := s.defs[name]
+	if !found || d.op == 0 {
+		return Equation{}, fmt.Errorf("%s is not a binary operation", name)
+	}
+
+	memo, visiting := make(map[string]*Expr), map[string]bool{name: true}
+	left, err := s.expand(d.left, memo, visiting)
+	if err != nil {
+		return Equation{}, err
+	}
+	right, err := s.expand(d.right, memo, visiting)
+	if err != nil {
+		return Equation{}, err
+	}
+	return Equation{left, right}, nil
+}
+
+func (s *System) expand(name string, memo map[string]*Expr, visiting map[string]bool) (*Expr, error) {
+	if e, found := memo[name]; found {
+		return e, nil
+	}
+	if s.free[name] {
+		memo[name] = Var(name)
+		return memo[name], nil
+	}
+	if visiting[name] {
+		return nil, fmt.Errorf("%s is defined in terms of itself", name)
+	}
+
+	d, found := s.defs[name]
+	if !found {
+		return nil, fmt.Errorf("%s is undefined", name)
+	}
+
+	var e *Expr
+	if d.op == 0 {
+		e = Const(d.value)
+	} else {
+		visiting[name] = true
+		left, err := s.expand(d.left, memo, visiting)
+		if err != nil {
+			return nil, err
+		}
+		right, err := s.expand(d.right, memo, visiting)
+		if err != nil {
+			return nil, err
+		}
+		delete(visiting, name)
+		e = Binary(d.op, left, right)
+	}
+
+	memo[name] = e
+	return e, nil
+}
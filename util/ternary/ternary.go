@@ -0,0 +1,191 @@
+package ternary
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Region is the set of addresses matching a pattern of fixed and floating
+// bits. Bits set in Float may take either value; Fixed holds the rest and is
+// zero wherever Float is set.
+type Region struct {
+	Fixed, Float uint64
+}
+
+func Address(addr uint64) Region { return Region{Fixed: addr} }
+
+// Size is the number of addresses in r, 2^(floating bits).
+func (r Region) Size() uint64 {
+	return 1 << bits.OnesCount64(r.Float)
+}
+
+func (r Region) Contains(addr uint64) bool {
+	return addr&^r.Float == r.Fixed
+}
+
+func (r Region) Intersects(o Region) bool {
+	return (r.Fixed^o.Fixed)&^(r.Float|o.Float) == 0
+}
+
+// Subtract returns disjoint regions covering r minus o.
+func (r Region) Subtract(o Region) []Region {
+	return r.appendSubtract(nil, o)
+}
+
+func (r Region) appendSubtract(pieces []Region, o Region) []Region {
+	if !r.Intersects(o) {
+		return append(pieces, r)
+	}
+
+	// Peel off one half of r per bit that o fixes but r leaves floating
+	rest := r
+	for free := r.Float &^ o.Float; free != 0; free &= free - 1 {
+		bit := free & -free
+		rest.Float &^= bit
+
+		outside := rest
+		outside.Fixed |= ^o.Fixed & bit
+		pieces = append(pieces, outside)
+
+		rest.Fixed |= o.Fixed & bit
+	}
+	return pieces
+}
+
+func (r Region) Format(width int) string {
+	var b strings.Builder
+	for i := width - 1; i >= 0; i-- {
+		bit := uint64(1) << i
+		switch {
+		case r.Float&bit != 0:
+			b.WriteByte('X')
+		case r.Fixed&bit != 0:
+			b.WriteByte('1')
+		default:
+			b.WriteByte('0')
+		}
+	}
+	return b.String()
+}
+
+// Mask is a bitmask string such as "000000000000000000000000000000X1001X".
+type Mask struct {
+	Ones, Zeros, Float uint64
+	Width              int
+}
+
+func ParseMask(s string) (Mask, error) {
+	m := Mask{Width: len(s)}
+	if m.Width > 64 {
+		return m, fmt.Errorf("mask %q wider than 64 bits", s)
+	}
+	for i := 0; i < len(s); i++ {
+		bit := uint64(1) << (len(s) - 1 - i)
+		switch s[i] {
+		case '1':
+			m.Ones |= bit
+		case '0':
+			m.Zeros |= bit
+		case 'X':
+			m.Float |= bit
+		default:
+			return m, fmt.Errorf("mask %q: bad bit %q", s, s[i])
+		}
+	}
+	return m, nil
+}
+
+// Value applies the mask as a version 1 decoder does to values: 0 and 1
+// overwrite, X leaves the bit alone.
+func (m Mask) Value(v uint64) uint64 {
+	return (v | m.Ones) &^ m.Zeros
+}
+
+// Decode applies the mask as a version 2 decoder does to addresses: 1
+// overwrites, 0 leaves the bit alone and X floats.
+func (m Mask) Decode(addr uint64) Region {
+	return Region{Fixed: (addr | m.Ones) &^ m.Float, Float: m.Float}
+}
+
+// Uncovered counts the addresses in r outside every region in others, by
+// subtracting each in turn from a list of disjoint pieces of r.
+func (r Region) Uncovered(others []Region) uint64 {
+	pieces := []Region{r}
+	for _, o := range others {
+		pieces = subtractAll(pieces, o)
+	}
+	size := uint64(0)
+	for _, p := range pieces {
+		size += p.Size()
+	}
+	return size
+}
+
+// subtractAll removes o from each of the disjoint regions in rs, reusing
+// rs's storage where it can.
+func subtractAll(rs []Region, o Region) []Region {
+	out := rs[:0]
+	var split []Region
+	for _, r := range rs {
+		if !r.Intersects(o) {
+			out = append(out, r)
+		} else {
+			split = r.appendSubtract(split, o)
+		}
+	}
+	return append(out, split...)
+}
+
+type write struct {
+	r     Region
+	value int
+}
+
+// Memory holds values written to whole regions at once, as disjoint regions:
+// each write is cut out of the regions written before it. Writes that fix
+// only a few bits and overlap heavily shred older regions into many pieces,
+// so a few dozen writes with 30 floating bits already make millions.
+type Memory struct {
+	writes []write
+	pieces []Region // scratch for Write
+}
+
+func (m *Memory) Write(r Region, value int) {
+	kept := m.writes[:0]
+	var split []write
+	for _, w := range m.writes {
+		if !w.r.Intersects(r) {
+			kept = append(kept, w)
+			continue
+		}
+		m.pieces = w.r.appendSubtract(m.pieces[:0], r)
+		for _, p := range m.pieces {
+			split = append(split, write{p, w.value})
+		}
+	}
+	m.writes = append(kept, split...)
+
+	// Unwritten addresses read 0 anyway
+	if value != 0 {
+		m.writes = append(m.writes, write{r, value})
+	}
+}
+
+func (m *Memory) Read(addr uint64) int {
+	for _, w := range m.writes {
+		if w.r.Contains(addr) {
+			return w.value
+		}
+	}
+	return 0
+}
+
+// Sum adds up every address's value without enumerating addresses.
+func (m *Memory) Sum() int {
+	sum := 0
+	for _, w := range m.writes {
+		sum += int(w.r.Size()) * w.value
+	}
+	return sum
+}
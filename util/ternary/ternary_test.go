@@ -0,0 +1,135 @@
+package ternary
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// randomMask floats float of the low width bits and sets the others at
+// random.
+func randomMask(rng *rand.Rand, width, float int) Mask {
+	m := Mask{Width: width}
+	for _, i := range rng.Perm(width) {
+		bit := uint64(1) << i
+		switch {
+		case float > 0:
+			m.Float |= bit
+			float--
+		case rng.Intn(2) == 0:
+			m.Ones |= bit
+		default:
+			m.Zeros |= bit
+		}
+	}
+	return m
+}
+
+// naive is memory with one entry per address.
+type naive map[uint64]int
+
+func (n naive) write(r Region, value int) {
+	for sub := r.Float; ; sub = (sub - 1) & r.Float {
+		n[r.Fixed|sub] = value
+		if sub == 0 {
+			break
+		}
+	}
+}
+
+func TestMemoryMatchesNaive(t *testing.T) {
+	rng := rand.New(rand.NewSource(14))
+	for round := 0; round < 50; round++ {
+		var mem Memory
+		want := make(naive)
+		for i := 0; i < 40; i++ {
+			r := randomMask(rng, 16, rng.Intn(10)).Decode(uint64(rng.Intn(1 << 16)))
+			value := rng.Intn(100)
+			mem.Write(r, value)
+			want.write(r, value)
+		}
+
+		sum := 0
+		for addr, value := range want {
+			sum += value
+			if got := mem.Read(addr); got != value {
+				t.Fatalf("round %d: Read(%d) = %d, want %d", round, addr, got, value)
+			}
+		}
+		if got := mem.Sum(); got != sum {
+			t.Fatalf("round %d: Sum() = %d, want %d", round, got, sum)
+		}
+	}
+}
+
+func TestUncovered(t *testing.T) {
+	rng := rand.New(rand.NewSource(14))
+	for round := 0; round < 200; round++ {
+		r := randomMask(rng, 12, rng.Intn(12)).Decode(uint64(rng.Intn(1 << 12)))
+		others := make([]Region, rng.Intn(8))
+		for i := range others {
+			others[i] = randomMask(rng, 12, rng.Intn(10)).Decode(uint64(rng.Intn(1 << 12)))
+		}
+
+		want := uint64(0)
+		for addr := uint64(0); addr < 1<<12; addr++ {
+			covered := false
+			for _, o := range others {
+				covered = covered || o.Contains(addr)
+			}
+			if r.Contains(addr) && !covered {
+				want++
+			}
+		}
+		if got := r.Uncovered(others); got != want {
+			t.Fatalf("%s minus %d regions: got %d, want %d", r.Format(12), len(others), got, want)
+		}
+	}
+}
+
+func TestSumSample(t *testing.T) {
+	var mem Memory
+	for _, w := range []struct {
+		mask  string
+		addr  uint64
+		value int
+	}{
+		{"000000000000000000000000000000X1001X", 42, 100},
+		{"00000000000000000000000000000000X0XX", 26, 1},
+	} {
+		m, err := ParseMask(w.mask)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mem.Write(m.Decode(w.addr), w.value)
+	}
+	if got := mem.Sum(); got != 208 {
+		t.Errorf("Sum() = %d, want 208", got)
+	}
+}
+
+// TestSumOverlapping writes many heavily floating, heavily overlapping
+// regions, the case that splitting on floating bits handled worst, and
+// checks the sum against one entry per address.
+func TestSumOverlapping(t *testing.T) {
+	rng := rand.New(rand.NewSource(14))
+	for _, tc := range []struct{ float, writes int }{
+		{14, 40}, {12, 200}, {8, 2000},
+	} {
+		var mem Memory
+		want := make(naive)
+		for i := 0; i < tc.writes; i++ {
+			r := randomMask(rng, 16, tc.float).Decode(uint64(rng.Intn(1 << 16)))
+			value := 1 + rng.Intn(1000)
+			mem.Write(r, value)
+			want.write(r, value)
+		}
+
+		sum := 0
+		for _, value := range want {
+			sum += value
+		}
+		if got := mem.Sum(); got != sum {
+			t.Errorf("%d writes with %d floating bits: Sum() = %d, want %d", tc.writes, tc.float, got, sum)
+		}
+	}
+}
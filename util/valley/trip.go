@@ -0,0 +1,127 @@
+package valley
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// Route is a trip through the valley: the expedition is at Path[i] at minute
+// Depart+i, and reaches each stop after the first at the matching minute in
+// Arrivals.
+type Route struct {
+	Depart   int
+	Path     []Pos
+	Arrivals []int
+}
+
+func (r *Route) Arrive() int { return r.Arrivals[len(r.Arrivals)-1] }
+
+// step is a state of the search: where the expedition is at a minute, and
+// which stop it is heading for.
+type step struct {
+	at       Pos
+	t, leg   int
+	estimate int
+	prev     int // index of the step before, or -1
+}
+
+type stepQueue struct {
+	steps []step
+	order []int
+}
+
+func (q stepQueue) Len() int { return len(q.order) }
+func (q stepQueue) Less(i, j int) bool {
+	a, b := q.steps[q.order[i]], q.steps[q.order[j]]
+	return a.t+a.estimate < b.t+b.estimate
+}
+func (q stepQueue) Swap(i, j int)       { q.order[i], q.order[j] = q.order[j], q.order[i] }
+func (q *stepQueue) Push(x interface{}) { q.order = append(q.order, x.(int)) }
+func (q *stepQueue) Pop() (popped interface{}) {
+	popped, q.order = q.order[len(q.order)-1], q.order[:len(q.order)-1]
+	return popped
+}
+
+// Trip finds the quickest way to leave stops[0] at minute depart and visit
+// the other stops in order, moving a step or waiting each minute and never
+// sharing a cell with a blizzard. It is an A* search over position, minute
+// within the blizzards' period and stop being headed for, so all the legs
+// are planned together; the estimate is the walk left if there were no
+// blizzards.
+func (v *Valley) Trip(depart int, stops ...Pos) (*Route, error) {
+	if len(stops) < 2 {
+		return nil, fmt.Errorf("valley: a trip needs at least two stops")
+	}
+	for _, p := range stops {
+		if !v.Inside(p) {
+			return nil, fmt.Errorf("valley: stop %d,%d is not in the valley", p.Row, p.Col)
+		}
+	}
+	if !v.Clear(stops[0], depart) {
+		return nil, fmt.Errorf("valley: a blizzard is on the first stop at minute %d", depart)
+	}
+
+	// rest[leg] is the walk from stop leg to the last one
+	rest := make([]int, len(stops))
+	for i := len(stops) - 2; i >= 0; i-- {
+		rest[i] = rest[i+1] + stops[i].manhattan(stops[i+1])
+	}
+	estimate := func(p Pos, leg int) int { return p.manhattan(stops[leg]) + rest[leg] }
+
+	cols := v.Width + 2
+	cells := (v.Height + 2) * cols
+	closed := make([]uint64, (len(stops)*v.Period*cells+63)/64)
+	key := func(s step) int { return ((s.leg*v.Period)+s.t%v.Period)*cells + s.at.Row*cols + s.at.Col }
+
+	q := &stepQueue{}
+	push := func(s step) {
+		for s.leg < len(stops) && s.at == stops[s.leg] {
+			s.leg++
+		}
+		if s.leg < len(stops) {
+			s.estimate = estimate(s.at, s.leg)
+		}
+		q.steps = append(q.steps, s)
+		heap.Push(q, len(q.steps)-1)
+	}
+	push(step{at: stops[0], t: depart, leg: 1, prev: -1})
+
+	for q.Len() > 0 {
+		i := heap.Pop(q).(int)
+		s := q.steps[i]
+		if s.leg == len(stops) {
+			return v.route(q.steps, i), nil
+		}
+		k := key(s)
+		if closed[k/64]>>(k%64)&1 == 1 {
+			continue
+		}
+		closed[k/64] |= 1 << (k % 64)
+
+		for _, d := range append([]Pos{{0, 0}}, moves[:]...) {
+			next := Pos{s.at.Row + d.Row, s.at.Col + d.Col}
+			if v.Clear(next, s.t+1) {
+				push(step{at: next, t: s.t + 1, leg: s.leg, prev: i})
+			}
+		}
+	}
+	return nil, fmt.Errorf("valley: the blizzards never let the expedition through")
+}
+
+func (v *Valley) route(steps []step, last int) *Route {
+	trail := make([]step, 0)
+	for i := last; i >= 0; i = steps[i].prev {
+		trail = append(trail, steps[i])
+	}
+
+	r := &Route{Depart: trail[len(trail)-1].t}
+	leg := 1
+	for i := len(trail) - 1; i >= 0; i-- {
+		s := trail[i]
+		r.Path = append(r.Path, s.at)
+		for ; leg < s.leg; leg++ {
+			r.Arrivals = append(r.Arrivals, s.t)
+		}
+	}
+	return r
+}
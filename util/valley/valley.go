@@ -0,0 +1,155 @@
+package valley
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Pos struct {
+	Row, Col int
+}
+
+func (p Pos) manhattan(q Pos) int {
+	return abs(p.Row-q.Row) + abs(p.Col-q.Col)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// directions blizzards may blow, in the order the puzzle draws them
+const arrows = "^v<>"
+
+var moves = [...]Pos{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+
+type blizzard struct {
+	Pos
+	dir int
+}
+
+// Valley is a walled basin with blizzards blowing across it, each keeping its
+// direction and wrapping round to the far wall. Start and End are the gaps in
+// the top and bottom walls. Positions count the walls, so the inside runs
+// from 1, 1 to Height, Width.
+type Valley struct {
+	Height, Width int
+	Start, End    Pos
+	Period        int // minutes until the blizzards are back where they began
+
+	blizzards []blizzard
+	blocked   [][]uint64 // bitset of inside cells with a blizzard, by minute
+}
+
+// Parse reads the puzzle's drawing of the valley.
+func Parse(input []string) (*Valley, error) {
+	if len(input) < 3 || len(input[0]) < 3 {
+		return nil, fmt.Errorf("valley: drawing is too small")
+	}
+	v := &Valley{Height: len(input) - 2, Width: len(input[0]) - 2}
+	for r, line := range input {
+		if len(line) != v.Width+2 {
+			return nil, fmt.Errorf("valley: line %d is %d wide, not %d", r+1, len(line), v.Width+2)
+		}
+		for c, ch := range line {
+			p := Pos{r, c}
+			wall := r == 0 || r == v.Height+1 || c == 0 || c == v.Width+1
+			switch {
+			case ch == '.' && r == 0:
+				v.Start = p
+			case ch == '.' && r == v.Height+1:
+				v.End = p
+			case ch == '#' && wall, ch == '.' && !wall:
+			case strings.ContainsRune(arrows, ch) && !wall:
+				v.blizzards = append(v.blizzards, blizzard{p, strings.IndexRune(arrows, ch)})
+			default:
+				return nil, fmt.Errorf("valley: line %d %q: unexpected %q at %d", r+1, line, ch, c+1)
+			}
+		}
+	}
+	if v.Start.Row != 0 || v.End.Row != v.Height+1 {
+		return nil, fmt.Errorf("valley: no gap in the top and bottom walls")
+	}
+
+	v.Period = v.Width / gcd(v.Width, v.Height) * v.Height
+	words := (v.Width*v.Height + 63) / 64
+	v.blocked = make([][]uint64, v.Period)
+	for t := range v.blocked {
+		v.blocked[t] = make([]uint64, words)
+		for _, b := range v.blizzards {
+			i := v.bit(v.blow(b, t))
+			v.blocked[t][i/64] |= 1 << (i % 64)
+		}
+	}
+	return v, nil
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func (v *Valley) bit(p Pos) int { return (p.Row-1)*v.Width + p.Col - 1 }
+
+// blow finds where b is after t minutes.
+func (v *Valley) blow(b blizzard, t int) Pos {
+	d := moves[b.dir]
+	r := ((b.Row-1+d.Row*t)%v.Height + v.Height) % v.Height
+	c := ((b.Col-1+d.Col*t)%v.Width + v.Width) % v.Width
+	return Pos{r + 1, c + 1}
+}
+
+// Inside reports whether p is in the valley or one of its gaps.
+func (v *Valley) Inside(p Pos) bool {
+	return p == v.Start || p == v.End ||
+		p.Row >= 1 && p.Row <= v.Height && p.Col >= 1 && p.Col <= v.Width
+}
+
+// Clear reports whether p is free of wall and blizzard at minute t.
+func (v *Valley) Clear(p Pos, t int) bool {
+	if !v.Inside(p) {
+		return false
+	}
+	if p == v.Start || p == v.End {
+		return true
+	}
+	i := v.bit(p)
+	return v.blocked[t%v.Period][i/64]>>(i%64)&1 == 0
+}
+
+// Frame draws the valley at minute t the way the puzzle does, with the
+// expedition as E at at.
+func (v *Valley) Frame(t int, at Pos) string {
+	counts := make(map[Pos]int)
+	last := make(map[Pos]byte)
+	for _, b := range v.blizzards {
+		p := v.blow(b, t)
+		counts[p]++
+		last[p] = arrows[b.dir]
+	}
+
+	var sb strings.Builder
+	for r := 0; r < v.Height+2; r++ {
+		for c := 0; c < v.Width+2; c++ {
+			p := Pos{r, c}
+			switch {
+			case p == at:
+				sb.WriteByte('E')
+			case !v.Inside(p):
+				sb.WriteByte('#')
+			case counts[p] > 1:
+				fmt.Fprint(&sb, counts[p]%10)
+			case counts[p] == 1:
+				sb.WriteByte(last[p])
+			default:
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
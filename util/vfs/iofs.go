@@ -0,0 +1,111 @@
+package vfs
+
+import (
+	"io"
+	"io/fs"
+	"time"
+)
+
+// Open makes FS an fs.FS, so fs.WalkDir, fs.Glob and testing/fstest work on
+// it. Files read as their size in zero bytes; a directory's Stat size is the
+// total beneath it.
+func (fsys *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	n, err := fsys.Lookup(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if n.IsDir {
+		return &openDir{node: n, name: name}, nil
+	}
+	return &openFile{node: n, name: name}, nil
+}
+
+// fileInfo describes a node under the name it was opened as.
+type fileInfo struct {
+	node *Node
+	name string
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return int64(fi.node.Size()) }
+func (fi fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi fileInfo) IsDir() bool        { return fi.node.IsDir }
+func (fi fileInfo) Sys() interface{}   { return fi.node }
+
+func (fi fileInfo) Mode() fs.FileMode {
+	if fi.node.IsDir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func info(n *Node) fileInfo {
+	if n.Parent == nil {
+		return fileInfo{n, "."}
+	}
+	return fileInfo{n, n.Name}
+}
+
+type openFile struct {
+	node   *Node
+	name   string
+	offset int64
+}
+
+func (f *openFile) Stat() (fs.FileInfo, error) { return info(f.node), nil }
+func (f *openFile) Close() error               { return nil }
+
+func (f *openFile) Read(b []byte) (int, error) {
+	left := int64(f.node.Size()) - f.offset
+	if left <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(b)) > left {
+		b = b[:left]
+	}
+	for i := range b {
+		b[i] = 0
+	}
+	f.offset += int64(len(b))
+	return len(b), nil
+}
+
+type openDir struct {
+	node    *Node
+	name    string
+	entries []*Node // nil until the first ReadDir
+	read    int
+}
+
+func (d *openDir) Stat() (fs.FileInfo, error) { return info(d.node), nil }
+func (d *openDir) Close() error               { return nil }
+
+func (d *openDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *openDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	if d.entries == nil {
+		d.entries = d.node.Children()
+	}
+
+	rest := d.entries[d.read:]
+	if count > 0 {
+		if len(rest) == 0 {
+			return nil, io.EOF
+		}
+		if len(rest) > count {
+			rest = rest[:count]
+		}
+	}
+	d.read += len(rest)
+
+	entries := make([]fs.DirEntry, len(rest))
+	for i, n := range rest {
+		entries[i] = fs.FileInfoToDirEntry(info(n))
+	}
+	return entries, nil
+}
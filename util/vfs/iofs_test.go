@@ -0,0 +1,59 @@
+package vfs
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+var transcript = []string{
+	"$ cd /",
+	"$ ls",
+	"dir a",
+	"14848514 b.txt",
+	"8504156 c.dat",
+	"dir d",
+	"$ cd a",
+	"$ ls",
+	"dir e",
+	"29116 f",
+	"2557 g",
+	"62596 h.lst",
+	"$ cd e",
+	"$ ls",
+	"584 i",
+	"$ cd ..",
+	"$ cd ..",
+	"$ cd d",
+	"$ ls",
+	"4060174 j",
+	"8033020 d.log",
+	"5626152 d.ext",
+	"7214296 k",
+}
+
+func TestFS(t *testing.T) {
+	fsys, err := ParseTranscript(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(fsys, "a/e/i", "a/f", "a/g", "a/h.lst", "b.txt", "c.dat", "d/j", "d/d.log", "d/d.ext", "d/k"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFSSizes(t *testing.T) {
+	fsys, err := ParseTranscript(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]int64{".": 48381165, "a": 94853, "a/e": 584, "d": 24933642, "a/e/i": 584} {
+		fi, err := fs.Stat(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Size() != want {
+			t.Errorf("%s: size %d, want %d", name, fi.Size(), want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package vfs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseTranscript rebuilds a filesystem from a terminal session of cd and ls
+// commands and their output.
+func ParseTranscript(lines []string) (*FS, error) {
+	fsys := New()
+	cwd := fsys.Root
+	listing := false
+
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		fail := func(format string, args ...interface{}) (*FS, error) {
+			return nil, fmt.Errorf("vfs: line %d %q: %s", i+1, line, fmt.Sprintf(format, args...))
+		}
+
+		if fields[0] == "$" {
+			listing = false
+			switch {
+			case len(fields) == 2 && fields[1] == "ls":
+				listing = true
+			case len(fields) == 3 && fields[1] == "cd":
+				switch fields[2] {
+				case "/":
+					cwd = fsys.Root
+				case "..":
+					if cwd.Parent == nil {
+						return fail("already at the root")
+					}
+					cwd = cwd.Parent
+				default:
+					// cd into a directory never listed still works on a real
+					// terminal, so take its word for it
+					dir, err := cwd.Mkdir(fields[2])
+					if err != nil {
+						return fail("%v", err)
+					}
+					cwd = dir
+				}
+			default:
+				return fail("unknown command")
+			}
+			continue
+		}
+
+		if !listing {
+			return fail("output outside of ls")
+		}
+		if len(fields) != 2 {
+			return fail("expected a size or dir and a name")
+		}
+		var err error
+		if fields[0] == "dir" {
+			_, err = cwd.Mkdir(fields[1])
+		} else if size, convErr := strconv.Atoi(fields[0]); convErr != nil {
+			return fail("bad size")
+		} else {
+			_, err = cwd.AddFile(fields[1], size)
+		}
+		if err != nil {
+			return fail("%v", err)
+		}
+	}
+	return fsys, nil
+}
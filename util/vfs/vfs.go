@@ -0,0 +1,216 @@
+package vfs
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Node is a file or directory. Files carry a size but no content;
+// directories cache the total size beneath them until something below
+// changes.
+type Node struct {
+	Name   string
+	Parent *Node
+	IsDir  bool
+
+	size     int // file size, or cached total for directories
+	stale    bool
+	children map[string]*Node
+}
+
+// FS is a tree of nodes under a root directory named "/".
+type FS struct {
+	Root *Node
+}
+
+func New() *FS {
+	return &FS{Root: newDir("/", nil)}
+}
+
+func newDir(name string, parent *Node) *Node {
+	return &Node{Name: name, Parent: parent, IsDir: true, children: make(map[string]*Node)}
+}
+
+// Size is a file's size, or the total size of the files under a directory.
+func (n *Node) Size() int {
+	if n.IsDir && n.stale {
+		n.size = 0
+		for _, child := range n.children {
+			n.size += child.Size()
+		}
+		n.stale = false
+	}
+	return n.size
+}
+
+func (n *Node) invalidate() {
+	for d := n; d != nil && !d.stale; d = d.Parent {
+		d.stale = true
+	}
+}
+
+func (n *Node) Path() string {
+	if n.Parent == nil {
+		return "/"
+	}
+	return path.Join(n.Parent.Path(), n.Name)
+}
+
+func (n *Node) Child(name string) *Node {
+	return n.children[name]
+}
+
+// Children lists a directory's entries sorted by name.
+func (n *Node) Children() []*Node {
+	children := make([]*Node, 0, len(n.children))
+	for _, child := range n.children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+	return children
+}
+
+func (n *Node) add(child *Node) (*Node, error) {
+	if !n.IsDir {
+		return nil, fmt.Errorf("vfs: %s is not a directory", n.Path())
+	}
+	if child.Name == "" || child.Name == "." || child.Name == ".." || strings.Contains(child.Name, "/") {
+		return nil, fmt.Errorf("vfs: bad name %q in %s", child.Name, n.Path())
+	}
+
+	if existing, ok := n.children[child.Name]; ok {
+		// A directory's size is its cached total, so only files compare sizes
+		if existing.IsDir != child.IsDir || (!child.IsDir && existing.size != child.size) {
+			return nil, fmt.Errorf("vfs: %s already exists", existing.Path())
+		}
+		return existing, nil
+	}
+	n.children[child.Name] = child
+	n.invalidate()
+	return child, nil
+}
+
+// Mkdir adds a subdirectory, or returns the one already there.
+func (n *Node) Mkdir(name string) (*Node, error) {
+	return n.add(newDir(name, n))
+}
+
+// AddFile adds a file. Listing the same file twice is harmless.
+func (n *Node) AddFile(name string, size int) (*Node, error) {
+	return n.add(&Node{Name: name, Parent: n, size: size})
+}
+
+// Lookup resolves a slash-separated path from the root; a leading slash is
+// optional.
+func (fsys *FS) Lookup(name string) (*Node, error) {
+	n := fsys.Root
+	for _, elem := range strings.Split(strings.Trim(name, "/"), "/") {
+		if elem == "" || elem == "." {
+			continue
+		}
+		if !n.IsDir {
+			return nil, fmt.Errorf("vfs: %s is not a directory", n.Path())
+		}
+		if n = n.children[elem]; n == nil {
+			return nil, fmt.Errorf("vfs: %s: %w", name, fs.ErrNotExist)
+		}
+	}
+	return n, nil
+}
+
+// Walk visits n and everything under it depth first, in name order. If fn
+// returns fs.SkipDir for a directory its contents are skipped; any other
+// error stops the walk and is returned.
+func (n *Node) Walk(fn func(n *Node) error) error {
+	if err := fn(n); err != nil {
+		if err == fs.SkipDir && n.IsDir {
+			return nil
+		}
+		return err
+	}
+	for _, child := range n.Children() {
+		if err := child.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Dirs lists every directory from n down, in walk order.
+func (n *Node) Dirs() []*Node {
+	dirs := make([]*Node, 0)
+	n.Walk(func(d *Node) error {
+		if d.IsDir {
+			dirs = append(dirs, d)
+		}
+		return nil
+	})
+	return dirs
+}
+
+// Glob returns the nodes whose paths match pattern, element by element as in
+// path.Match, in walk order.
+func (fsys *FS) Glob(pattern string) ([]*Node, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	elems := strings.Split(strings.Trim(pattern, "/"), "/")
+	matches := make([]*Node, 0)
+	var match func(n *Node, elems []string)
+	match = func(n *Node, elems []string) {
+		if len(elems) == 0 {
+			matches = append(matches, n)
+			return
+		}
+		for _, child := range n.Children() {
+			if ok, _ := path.Match(elems[0], child.Name); ok {
+				match(child, elems[1:])
+			}
+		}
+	}
+	match(fsys.Root, elems)
+	return matches, nil
+}
+
+// Du lists the total size of each directory under n, deepest first, as du
+// does.
+func (n *Node) Du() string {
+	var b strings.Builder
+	var du func(d *Node)
+	du = func(d *Node) {
+		for _, child := range d.Children() {
+			if child.IsDir {
+				du(child)
+			}
+		}
+		fmt.Fprintf(&b, "%d\t%s\n", d.Size(), d.Path())
+	}
+	if n.IsDir {
+		du(n)
+	}
+	return b.String()
+}
+
+// Tree draws n and everything under it as the puzzle describes its
+// filesystem.
+func (n *Node) Tree() string {
+	var b strings.Builder
+	n.tree(&b, 0)
+	return b.String()
+}
+
+func (n *Node) tree(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	if n.IsDir {
+		fmt.Fprintf(b, "- %s (dir)\n", n.Name)
+	} else {
+		fmt.Fprintf(b, "- %s (file, size=%d)\n", n.Name, n.size)
+	}
+	for _, child := range n.Children() {
+		child.tree(b, depth+1)
+	}
+}
@@ -0,0 +1,44 @@
+package vfs
+
+import "testing"
+
+func TestMkdirAfterSize(t *testing.T) {
+	fsys := New()
+	a, err := fsys.Root.Mkdir("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.AddFile("x", 10); err != nil {
+		t.Fatal(err)
+	}
+	if got := fsys.Root.Size(); got != 10 {
+		t.Fatalf("root size %d, want 10", got)
+	}
+
+	again, err := fsys.Root.Mkdir("a")
+	if err != nil {
+		t.Fatalf("Mkdir of an existing directory: %v", err)
+	}
+	if again != a {
+		t.Errorf("Mkdir returned a new node for an existing directory")
+	}
+	if got := fsys.Root.Size(); got != 10 {
+		t.Errorf("root size %d after Mkdir again, want 10", got)
+	}
+}
+
+func TestAddFileConflicts(t *testing.T) {
+	fsys := New()
+	if _, err := fsys.Root.AddFile("x", 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fsys.Root.AddFile("x", 10); err != nil {
+		t.Errorf("adding the same file again: %v", err)
+	}
+	if _, err := fsys.Root.AddFile("x", 11); err == nil {
+		t.Errorf("adding x with a different size succeeded")
+	}
+	if _, err := fsys.Root.Mkdir("x"); err == nil {
+		t.Errorf("making a directory over file x succeeded")
+	}
+}
@@ -0,0 +1,136 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+)
+
+type IssueKind int
+
+const (
+	UnreachableWorkflow IssueKind = iota
+	UnreachableRule
+	NeverMatches
+	RedundantRule
+	ConstantWorkflow
+)
+
+// Issue flags a workflow, or one of its rules when Rule >= 0.
+type Issue struct {
+	Kind     IssueKind
+	Workflow string
+	Rule     int
+	Reason   string
+}
+
+func (i Issue) String() string {
+	if i.Rule < 0 {
+		return fmt.Sprintf("%s: %s", i.Workflow, i.Reason)
+	}
+	return fmt.Sprintf("%s rule %d: %s", i.Workflow, i.Rule, i.Reason)
+}
+
+type analysis struct {
+	s       System
+	reached map[string][]bool
+	matched map[string][]bool
+	outcome map[string]string
+}
+
+// Analyze sends every part in b through the workflows and reports rules that
+// can never apply, and rules whose removal would not change any outcome.
+func (s System) Analyze(b Box) ([]Issue, error) {
+	if _, err := s.Compile(); err != nil {
+		return nil, err
+	}
+
+	a := analysis{
+		s:       s,
+		reached: make(map[string][]bool),
+		matched: make(map[string][]bool),
+		outcome: map[string]string{Accepted: Accepted, Rejected: Rejected},
+	}
+	a.visit(Start, b)
+
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	issues := make([]Issue, 0)
+	for _, name := range names {
+		w := s[name]
+		reached, entered := a.reached[name]
+		if !entered {
+			issues = append(issues, Issue{UnreachableWorkflow, name, -1, "never entered"})
+			continue
+		}
+
+		if out := a.constant(name); out != "" && len(w.Rules) > 1 {
+			issues = append(issues, Issue{ConstantWorkflow, name, -1, "always ends at " + out})
+		}
+
+		for i, r := range w.Rules {
+			switch {
+			case !reached[i]:
+				issues = append(issues, Issue{UnreachableRule, name, i, "earlier rules catch every part"})
+			case !r.Unconditional() && !a.matched[name][i]:
+				issues = append(issues, Issue{NeverMatches, name, i, r.Condition() + " never holds here"})
+			case !r.Unconditional():
+				if out := a.constant(r.Dest); out != "" && out == a.constantFrom(name, i+1) {
+					reason := fmt.Sprintf("%s and the rules after it all end at %s", r.Condition(), out)
+					issues = append(issues, Issue{RedundantRule, name, i, reason})
+				}
+			}
+		}
+	}
+	return issues, nil
+}
+
+func (a *analysis) visit(name string, b Box) {
+	w, found := a.s[name]
+	if !found {
+		return
+	}
+	if _, found := a.reached[name]; !found {
+		a.reached[name] = make([]bool, len(w.Rules))
+		a.matched[name] = make([]bool, len(w.Rules))
+	}
+
+	for i, r := range w.Rules {
+		if b.Empty() {
+			return
+		}
+		a.reached[name][i] = true
+
+		matched, rest := b.Split(r)
+		if !matched.Empty() {
+			a.matched[name][i] = true
+			a.visit(r.Dest, matched)
+		}
+		b = rest
+	}
+}
+
+// constant returns the outcome every part reaching name gets regardless of its
+// ratings, or "" if that depends on the part.
+func (a *analysis) constant(name string) string {
+	if out, found := a.outcome[name]; found {
+		return out
+	}
+	a.outcome[name] = ""
+	a.outcome[name] = a.constantFrom(name, 0)
+	return a.outcome[name]
+}
+
+func (a *analysis) constantFrom(name string, first int) string {
+	rules := a.s[name].Rules[first:]
+	out := a.constant(rules[0].Dest)
+	for _, r := range rules[1:] {
+		if a.constant(r.Dest) != out {
+			return ""
+		}
+	}
+	return out
+}
@@ -0,0 +1,212 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Box is an inclusive range of ratings per category.
+type Box [4][2]int
+
+func FullBox(min, max int) Box {
+	r := [2]int{min, max}
+	return Box{r, r, r, r}
+}
+
+func (b Box) Empty() bool {
+	for _, r := range b {
+		if r[0] > r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func (b Box) Volume() int {
+	if b.Empty() {
+		return 0
+	}
+	product := 1
+	for _, r := range b {
+		product *= r[1] - r[0] + 1
+	}
+	return product
+}
+
+func (b Box) Contains(p Part) bool {
+	for i, r := range b {
+		if p[i] < r[0] || p[i] > r[1] {
+			return false
+		}
+	}
+	return true
+}
+
+func (b Box) String() string {
+	ranges := make([]string, len(b))
+	for i, r := range b {
+		ranges[i] = fmt.Sprintf("%c=%d..%d", Categories[i], r[0], r[1])
+	}
+	return "{" + strings.Join(ranges, ",") + "}"
+}
+
+// Split divides b into the parts r matches and the parts it passes on.
+// Either may be empty.
+func (b Box) Split(r Rule) (matched, rest Box) {
+	matched, rest = b, b
+	switch r.Op {
+	case '<':
+		matched[r.Category][1] = r.Value - 1
+		rest[r.Category][0] = r.Value
+	case '>':
+		matched[r.Category][0] = r.Value + 1
+		rest[r.Category][1] = r.Value
+	default:
+		rest[0] = [2]int{1, 0}
+	}
+	return
+}
+
+// Node is one rule in the compiled decision tree. Parts matching Test go to
+// Then, the rest to Else, which is nil for fallback rules. Leaves have only an
+// Outcome, Accepted or Rejected.
+type Node struct {
+	Workflow   string
+	Rule       int
+	Test       Rule
+	Then, Else *Node
+	Outcome    string
+}
+
+func (n *Node) Leaf() bool { return n.Outcome != "" }
+
+// Compile turns the workflows into a decision tree rooted at Start. Workflows
+// used from several places become shared subtrees.
+func (s System) Compile() (*Node, error) {
+	c := compiler{
+		s:        s,
+		nodes:    make(map[ruleRef]*Node),
+		visiting: make(map[string]bool),
+		leaves: map[string]*Node{
+			Accepted: {Outcome: Accepted},
+			Rejected: {Outcome: Rejected},
+		},
+	}
+	return c.compile(Start, 0)
+}
+
+type ruleRef struct {
+	workflow string
+	rule     int
+}
+
+type compiler struct {
+	s        System
+	nodes    map[ruleRef]*Node
+	visiting map[string]bool
+	leaves   map[string]*Node
+}
+
+func (c *compiler) compile(name string, i int) (*Node, error) {
+	if leaf, found := c.leaves[name]; found {
+		return leaf, nil
+	}
+
+	w, found := c.s[name]
+	if !found {
+		return nil, fmt.Errorf("undefined workflow %s", name)
+	}
+	key := ruleRef{name, i}
+	if n, found := c.nodes[key]; found {
+		return n, nil
+	}
+
+	if i == 0 {
+		if c.visiting[name] {
+			return nil, fmt.Errorf("workflow %s can loop back to itself", name)
+		}
+		c.visiting[name] = true
+		defer delete(c.visiting, name)
+	}
+
+	n := &Node{Workflow: name, Rule: i, Test: w.Rules[i]}
+	var err error
+	if n.Then, err = c.compile(n.Test.Dest, 0); err != nil {
+		return nil, err
+	}
+	if !n.Test.Unconditional() {
+		if n.Else, err = c.compile(name, i+1); err != nil {
+			return nil, err
+		}
+	}
+
+	c.nodes[key] = n
+	return n, nil
+}
+
+func (n *Node) String() string {
+	var b strings.Builder
+	n.write(&b, 0)
+	return b.String()
+}
+
+func (n *Node) write(b *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	if n.Leaf() {
+		b.WriteString(indent + n.Outcome + "\n")
+		return
+	}
+
+	fmt.Fprintf(b, "%s%s[%d] %s:\n", indent, n.Workflow, n.Rule, n.Test.Condition())
+	n.Then.write(b, depth+1)
+	if n.Else != nil {
+		n.Else.write(b, depth)
+	}
+}
+
+// Region is a box of parts that all follow the same path.
+type Region struct {
+	Box   Box
+	Steps []Step
+}
+
+func (r Region) String() string {
+	names := make([]string, len(r.Steps))
+	for i, s := range r.Steps {
+		names[i] = s.Workflow + "[" + s.Rule.Condition() + "]"
+	}
+	return r.Box.String() + " via " + strings.Join(names, " -> ")
+}
+
+// Regions lists the disjoint boxes within b that end at outcome.
+func (n *Node) Regions(b Box, outcome string) []Region {
+	regions := make([]Region, 0)
+	n.collect(b, outcome, nil, &regions)
+	return regions
+}
+
+func (n *Node) collect(b Box, outcome string, steps []Step, regions *[]Region) {
+	if b.Empty() {
+		return
+	}
+	if n.Leaf() {
+		if n.Outcome == outcome {
+			*regions = append(*regions, Region{b, append([]Step(nil), steps...)})
+		}
+		return
+	}
+
+	matched, rest := b.Split(n.Test)
+	n.Then.collect(matched, outcome, append(steps, Step{n.Workflow, n.Test}), regions)
+	if n.Else != nil {
+		n.Else.collect(rest, outcome, steps, regions)
+	}
+}
+
+func (n *Node) AcceptedVolume(b Box) int {
+	sum := 0
+	for _, r := range n.Regions(b, Accepted) {
+		sum += r.Box.Volume()
+	}
+	return sum
+}
@@ -0,0 +1,235 @@
+package workflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	Start    = "in"
+	Accepted = "A"
+	Rejected = "R"
+
+	Categories = "xmas"
+)
+
+type Part [4]int
+
+func ParsePart(line string) (Part, error) {
+	var p Part
+	_, err := fmt.Sscanf(line, "{x=%d,m=%d,a=%d,s=%d}", &p[0], &p[1], &p[2], &p[3])
+	return p, err
+}
+
+func (p Part) RatingSum() int {
+	return p[0] + p[1] + p[2] + p[3]
+}
+
+func (p Part) String() string {
+	return fmt.Sprintf("{x=%d,m=%d,a=%d,s=%d}", p[0], p[1], p[2], p[3])
+}
+
+// Rule sends parts to Dest if part[Category] Op Value holds. The last rule of
+// every workflow is the unconditional fallback, with Op == 0.
+type Rule struct {
+	Category int
+	Op       byte
+	Value    int
+	Dest     string
+}
+
+func (r Rule) Unconditional() bool { return r.Op == 0 }
+
+func (r Rule) Matches(p Part) bool {
+	switch r.Op {
+	case '<':
+		return p[r.Category] < r.Value
+	case '>':
+		return p[r.Category] > r.Value
+	default:
+		return true
+	}
+}
+
+func (r Rule) String() string {
+	if r.Unconditional() {
+		return r.Dest
+	}
+	return fmt.Sprintf("%c%c%d:%s", Categories[r.Category], r.Op, r.Value, r.Dest)
+}
+
+// Condition renders just the test, or "else" for the fallback.
+func (r Rule) Condition() string {
+	if r.Unconditional() {
+		return "else"
+	}
+	return fmt.Sprintf("%c%c%d", Categories[r.Category], r.Op, r.Value)
+}
+
+type Workflow struct {
+	Name  string
+	Rules []Rule
+}
+
+func (w *Workflow) String() string {
+	rules := make([]string, len(w.Rules))
+	for i, r := range w.Rules {
+		rules[i] = r.String()
+	}
+	return w.Name + "{" + strings.Join(rules, ",") + "}"
+}
+
+// Match returns the index of the first rule that matches p.
+func (w *Workflow) Match(p Part) int {
+	for i, r := range w.Rules {
+		if r.Matches(p) {
+			return i
+		}
+	}
+	return len(w.Rules) - 1
+}
+
+func ParseWorkflow(line string) (*Workflow, error) {
+	name, after, found := strings.Cut(line, "{")
+	if !found || !strings.HasSuffix(after, "}") || name == "" {
+		return nil, fmt.Errorf("workflow %q: expected name{rules}", line)
+	}
+
+	tokens := strings.Split(strings.TrimSuffix(after, "}"), ",")
+	w := &Workflow{Name: name, Rules: make([]Rule, len(tokens))}
+	for i, t := range tokens {
+		if i == len(tokens)-1 {
+			if strings.Contains(t, ":") {
+				return nil, fmt.Errorf("workflow %q: last rule must be unconditional", line)
+			}
+			w.Rules[i] = Rule{Dest: t}
+			continue
+		}
+
+		cond, dest, found := strings.Cut(t, ":")
+		if !found || len(cond) < 3 || dest == "" {
+			return nil, fmt.Errorf("workflow %q: bad rule %q", line, t)
+		}
+
+		category := strings.IndexByte(Categories, cond[0])
+		if category < 0 {
+			return nil, fmt.Errorf("workflow %q: unknown category in %q", line, t)
+		}
+		if cond[1] != '<' && cond[1] != '>' {
+			return nil, fmt.Errorf("workflow %q: unknown operator in %q", line, t)
+		}
+		value, err := strconv.Atoi(cond[2:])
+		if err != nil {
+			return nil, fmt.Errorf("workflow %q: %w", line, err)
+		}
+
+		w.Rules[i] = Rule{category, cond[1], value, dest}
+	}
+	return w, nil
+}
+
+type System map[string]*Workflow
+
+// Parse reads workflows up to the first blank line and parts after it.
+func Parse(input []string) (System, []Part, error) {
+	s := make(System)
+	i := 0
+	for ; i < len(input) && input[i] != ""; i++ {
+		w, err := ParseWorkflow(input[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, found := s[w.Name]; found {
+			return nil, nil, fmt.Errorf("workflow %s defined twice", w.Name)
+		}
+		s[w.Name] = w
+	}
+
+	parts := make([]Part, 0)
+	for _, line := range input[i:] {
+		if line == "" {
+			continue
+		}
+		p, err := ParsePart(line)
+		if err != nil {
+			return nil, nil, fmt.Errorf("part %q: %w", line, err)
+		}
+		parts = append(parts, p)
+	}
+
+	return s, parts, s.validate()
+}
+
+func (s System) validate() error {
+	for _, w := range s {
+		for _, r := range w.Rules {
+			if _, found := s[r.Dest]; !found && r.Dest != Accepted && r.Dest != Rejected {
+				return fmt.Errorf("workflow %s sends parts to undefined %s", w.Name, r.Dest)
+			}
+		}
+	}
+	if _, found := s[Start]; !found {
+		return fmt.Errorf("no %s workflow", Start)
+	}
+	return nil
+}
+
+// Step is one workflow visited by a part, and the rule that sent it onward.
+type Step struct {
+	Workflow string
+	Rule     Rule
+}
+
+type Trace struct {
+	Part    Part
+	Steps   []Step
+	Outcome string
+}
+
+func (t Trace) Accepted() bool { return t.Outcome == Accepted }
+
+// String lists the workflow names, e.g. "in -> qqz -> qs -> lnx -> A".
+func (t Trace) String() string {
+	names := make([]string, 0, len(t.Steps)+1)
+	for _, s := range t.Steps {
+		names = append(names, s.Workflow)
+	}
+	names = append(names, t.Outcome)
+	return strings.Join(names, " -> ")
+}
+
+// Explain also gives the rule that fired in each workflow.
+func (t Trace) Explain() string {
+	var b strings.Builder
+	verdict := "rejected"
+	if t.Accepted() {
+		verdict = "accepted"
+	}
+	fmt.Fprintf(&b, "%s %s: %s", t.Part, verdict, t)
+	for _, s := range t.Steps {
+		fmt.Fprintf(&b, "\n  %s: %s -> %s", s.Workflow, s.Rule.Condition(), s.Rule.Dest)
+	}
+	return b.String()
+}
+
+// Run sends p through the workflows from Start, failing on a loop.
+func (s System) Run(p Part) (Trace, error) {
+	t := Trace{Part: p}
+	visited := make(map[string]bool)
+	for name := Start; ; {
+		if name == Accepted || name == Rejected {
+			t.Outcome = name
+			return t, nil
+		}
+		if visited[name] {
+			return t, fmt.Errorf("part %s loops back to workflow %s", p, name)
+		}
+		visited[name] = true
+
+		w := s[name]
+		r := w.Rules[w.Match(p)]
+		t.Steps = append(t.Steps, Step{name, r})
+		name = r.Dest
+	}
+}